@@ -0,0 +1,47 @@
+package alarms
+
+import (
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/coinbase/odin/aws"
+)
+
+// DeleteAlarms accepts at most 100 alarm names per call
+const maxDeleteAlarms = 100
+
+// FindByPrefix returns all metric alarms with a name starting with prefix
+func FindByPrefix(cwc aws.CWAPI, prefix string) ([]*cloudwatch.MetricAlarm, error) {
+	alarms := []*cloudwatch.MetricAlarm{}
+
+	pagefn := func(page *cloudwatch.DescribeAlarmsOutput, lastPage bool) bool {
+		alarms = append(alarms, page.MetricAlarms...)
+		return !lastPage
+	}
+
+	err := cwc.DescribeAlarmsPages(&cloudwatch.DescribeAlarmsInput{
+		AlarmNamePrefix: &prefix,
+	}, pagefn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return alarms, nil
+}
+
+// Teardown deletes the alarms
+func Teardown(cwc aws.CWAPI, names []*string) error {
+	for len(names) > 0 {
+		batch := names
+		if len(batch) > maxDeleteAlarms {
+			batch = names[:maxDeleteAlarms]
+		}
+
+		if _, err := cwc.DeleteAlarms(&cloudwatch.DeleteAlarmsInput{AlarmNames: batch}); err != nil {
+			return err
+		}
+
+		names = names[len(batch):]
+	}
+
+	return nil
+}
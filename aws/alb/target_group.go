@@ -3,6 +3,7 @@ package alb
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/service/elbv2"
 	"github.com/coinbase/odin/aws"
@@ -87,10 +88,11 @@ func createDescribeTargetHealthInput(arn *string, instances []string) *elbv2.Des
 // Find
 //////
 
-// FindAll returns all target groups in a list
-func FindAll(albc aws.ALBAPI, names []*string) ([]*TargetGroup, error) {
+// FindAll returns all target groups in a list, each either a name or an ARN
+// An ARN can reference a target group in any VPC of the account
+func FindAll(albc aws.ALBAPI, namesOrARNs []*string) ([]*TargetGroup, error) {
 	tgs := []*TargetGroup{}
-	for _, name := range names {
+	for _, name := range namesOrARNs {
 		tg, err := find(albc, name)
 		if err != nil {
 			return nil, err
@@ -101,8 +103,13 @@ func FindAll(albc aws.ALBAPI, names []*string) ([]*TargetGroup, error) {
 	return tgs, nil
 }
 
-func find(alb aws.ALBAPI, targetGroupName *string) (*TargetGroup, error) {
-	awsTarget, err := findByName(alb, targetGroupName)
+func find(alb aws.ALBAPI, nameOrARN *string) (*TargetGroup, error) {
+	findFn := findByName
+	if isARN(*nameOrARN) {
+		findFn = findByARN
+	}
+
+	awsTarget, err := findFn(alb, nameOrARN)
 	if err != nil {
 		return nil, err
 	}
@@ -120,7 +127,7 @@ func find(alb aws.ALBAPI, targetGroupName *string) (*TargetGroup, error) {
 		ServiceNameTag:    aws.FetchELBV2Tag(awsTags, to.Strp("ServiceName")),
 		AllowedServiceTag: aws.FetchELBV2Tag(awsTags, to.Strp("AllowedService")),
 		TargetGroupArn:    awsTarget.TargetGroupArn,
-		TargetGroupName:   targetGroupName,
+		TargetGroupName:   awsTarget.TargetGroupName,
 		SlowStartDuration: slowStartDuration,
 	}, nil
 }
@@ -145,6 +152,31 @@ func findByName(alb aws.ALBAPI, targetGroupName *string) (*elbv2.TargetGroup, er
 	return elbsOutput.TargetGroups[0], nil
 }
 
+func findByARN(alb aws.ALBAPI, targetGroupARN *string) (*elbv2.TargetGroup, error) {
+	elbsOutput, err := alb.DescribeTargetGroups(&elbv2.DescribeTargetGroupsInput{
+		TargetGroupArns: []*string{targetGroupARN},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(elbsOutput.TargetGroups) != 1 {
+		return nil, fmt.Errorf("TargetGroup Not Found")
+	}
+
+	if *elbsOutput.TargetGroups[0].TargetGroupArn != *targetGroupARN {
+		return nil, fmt.Errorf("TargetGroup Not Found")
+	}
+
+	return elbsOutput.TargetGroups[0], nil
+}
+
+// isARN sees if a string is a target group ARN
+func isARN(name string) bool {
+	return strings.HasPrefix(name, "arn:")
+}
+
 func findTagsByName(alb aws.ALBAPI, targetGroupARN *string) ([]*elbv2.Tag, error) {
 	tagsOutput, err := alb.DescribeTags(&elbv2.DescribeTagsInput{
 		ResourceArns: []*string{targetGroupARN},
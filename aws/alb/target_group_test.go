@@ -57,6 +57,22 @@ func Test_FindAll_Found(t *testing.T) {
 	assert.Equal(t, *am[1].TargetGroupArn, "tg_other_name")
 }
 
+func Test_FindAll_ARN(t *testing.T) {
+	arn := "arn:aws:elasticloadbalancing:us-east-1:000000000000:targetgroup/shared/0123456789abcdef"
+
+	albc := &mocks.ALBClient{}
+	_, err := FindAll(albc, []*string{to.Strp(arn)})
+	assert.Error(t, err)
+
+	albc.AddTargetGroup(mocks.MockTargetGroup{Name: arn})
+	am, err := FindAll(albc, []*string{to.Strp(arn)})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(am))
+	assert.Equal(t, arn, *am[0].TargetGroupArn)
+	assert.Equal(t, "project_name", *am[0].ProjectName())
+}
+
 func Test_GetInstances(t *testing.T) {
 	albc := &mocks.ALBClient{}
 	albc.AddTargetGroup(mocks.MockTargetGroup{})
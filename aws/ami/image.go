@@ -10,8 +10,17 @@ import (
 
 // Image struct
 type Image struct {
-	ImageID       *string
-	DeployWithTag *string
+	ImageID            *string
+	DeployWithTag      *string
+	Architecture       *string
+	VirtualizationType *string
+	OwnerID            *string
+
+	// ISO 8601 dates, DeprecationTime is nil if the image is not deprecated
+	CreationDate    *string
+	DeprecationTime *string
+
+	Tags map[string]string
 }
 
 func isID(name string) bool {
@@ -63,11 +72,46 @@ func find(ec2c aws.EC2API, in *ec2.DescribeImagesInput) (*Image, error) {
 		if im == nil {
 			return nil, fmt.Errorf("AMI Image nil")
 		}
-		return &Image{
-			im.ImageId,
-			aws.FetchEc2Tag(im.Tags, to.Strp("DeployWith")),
-		}, nil
+		return newImage(im), nil
 	default:
 		return nil, fmt.Errorf("Must be exactly 1 Image with tag Name, there are %v", len(output.Images))
 	}
 }
+
+func newImage(im *ec2.Image) *Image {
+	return &Image{
+		ImageID:            im.ImageId,
+		DeployWithTag:      aws.FetchEc2Tag(im.Tags, to.Strp("DeployWith")),
+		Architecture:       im.Architecture,
+		VirtualizationType: im.VirtualizationType,
+		OwnerID:            im.OwnerId,
+		CreationDate:       im.CreationDate,
+		DeprecationTime:    im.DeprecationTime,
+		Tags:               imageTags(im.Tags),
+	}
+}
+
+func imageTags(tags []*ec2.Tag) map[string]string {
+	m := map[string]string{}
+	for _, tag := range tags {
+		if tag == nil || tag.Key == nil || tag.Value == nil {
+			continue
+		}
+		m[*tag.Key] = *tag.Value
+	}
+	return m
+}
+
+// FindInOwnerAccount returns the image as seen by its owner, tags on shared images are only visible in the owner account
+func FindInOwnerAccount(ownerEC2 aws.EC2API, id *string, ownerID *string) (*Image, error) {
+	im, err := find(ownerEC2, &ec2.DescribeImagesInput{ImageIds: []*string{id}, Owners: []*string{ownerID}})
+	if err != nil {
+		return nil, err
+	}
+
+	if im == nil {
+		return nil, fmt.Errorf("AMI Image %v not found in owner account %v", *id, *ownerID)
+	}
+
+	return im, nil
+}
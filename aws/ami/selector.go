@@ -0,0 +1,131 @@
+package ami
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/utils/to"
+)
+
+// Selector finds an image by its tags and owners, tag values can use the * and ? wildcards
+// e.g. {"tags": {"Name": "ubuntu-base"}, "owners": ["self"], "latest": true}
+type Selector struct {
+	Tags   map[string]string `json:"tags,omitempty"`
+	Owners []*string         `json:"owners,omitempty"`
+
+	// Latest picks the newest image by CreationDate, otherwise exactly one image must match
+	Latest bool `json:"latest,omitempty"`
+}
+
+// ValidateAttributes validates attributes
+func (sel *Selector) ValidateAttributes() error {
+	if len(sel.Tags) == 0 {
+		return fmt.Errorf("AMI selector must have at least one tag")
+	}
+
+	for key, value := range sel.Tags {
+		if key == "" || value == "" {
+			return fmt.Errorf("AMI selector tags must have a key and value")
+		}
+	}
+
+	for _, owner := range sel.Owners {
+		if owner == nil || *owner == "" {
+			return fmt.Errorf("AMI selector owners must not be empty")
+		}
+	}
+
+	return nil
+}
+
+// String returns a description of the selector for errors and logs
+func (sel *Selector) String() string {
+	keys := []string{}
+	for key := range sel.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	tags := []string{}
+	for _, key := range keys {
+		tags = append(tags, fmt.Sprintf("%v=%v", key, sel.Tags[key]))
+	}
+
+	return fmt.Sprintf("tags %v owners %v latest %v", tags, to.StrSlice(sel.Owners), sel.Latest)
+}
+
+// FindBySelector returns the image the selector resolves to
+func FindBySelector(ec2c aws.EC2API, sel *Selector) (*Image, error) {
+	return findBySelector(ec2c, sel, nil)
+}
+
+// FindPinnedBySelector returns the image with the pinned ID, erroring if the selector does not match it
+func FindPinnedBySelector(ec2c aws.EC2API, sel *Selector, id *string) (*Image, error) {
+	if id == nil {
+		return nil, fmt.Errorf("AMI ID nil")
+	}
+
+	im, err := findBySelector(ec2c, sel, id)
+	if err != nil {
+		return nil, fmt.Errorf("AMI %v does not match the selector: %v", *id, err.Error())
+	}
+
+	return im, nil
+}
+
+func findBySelector(ec2c aws.EC2API, sel *Selector, id *string) (*Image, error) {
+	if sel == nil {
+		return nil, fmt.Errorf("AMI selector nil")
+	}
+
+	keys := []string{}
+	for key := range sel.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	filters := []*ec2.Filter{}
+	for _, key := range keys {
+		filters = append(filters, &ec2.Filter{
+			Name:   to.Strp(fmt.Sprintf("tag:%v", key)),
+			Values: []*string{to.Strp(sel.Tags[key])},
+		})
+	}
+
+	in := &ec2.DescribeImagesInput{Filters: filters}
+	if len(sel.Owners) > 0 {
+		in.Owners = sel.Owners
+	}
+
+	if id != nil {
+		in.ImageIds = []*string{id}
+	}
+
+	output, err := ec2c.DescribeImages(in)
+	if err != nil {
+		return nil, err
+	}
+
+	images := []*ec2.Image{}
+	for _, im := range output.Images {
+		if im != nil && im.ImageId != nil && (id == nil || *im.ImageId == *id) {
+			images = append(images, im)
+		}
+	}
+
+	switch {
+	case len(images) == 0:
+		return nil, fmt.Errorf("No AMI Image found for %v", sel.String())
+	case len(images) > 1 && !sel.Latest:
+		return nil, fmt.Errorf("Must be exactly 1 AMI Image for %v, there are %v", sel.String(), len(images))
+	}
+
+	// CreationDate is ISO 8601 so sorts as a string
+	sort.Slice(images, func(i, j int) bool {
+		return to.Strs(images[i].CreationDate) > to.Strs(images[j].CreationDate)
+	})
+
+	return newImage(images[0]), nil
+}
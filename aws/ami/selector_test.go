@@ -0,0 +1,62 @@
+package ami
+
+import (
+	"testing"
+
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Selector_ValidateAttributes(t *testing.T) {
+	assert.Error(t, (&Selector{}).ValidateAttributes())
+	assert.Error(t, (&Selector{Tags: map[string]string{"Name": ""}}).ValidateAttributes())
+	assert.Error(t, (&Selector{Tags: map[string]string{"Name": "ubuntu"}, Owners: []*string{to.Strp("")}}).ValidateAttributes())
+	assert.NoError(t, (&Selector{Tags: map[string]string{"Name": "ubuntu"}, Owners: []*string{to.Strp("self")}}).ValidateAttributes())
+}
+
+func Test_FindBySelector_Latest(t *testing.T) {
+	ec2c := &mocks.EC2Client{}
+	ec2c.AddImageVersion("ubuntu-base", "ami-000001", "2024-01-01T00:00:00.000Z")
+	ec2c.AddImageVersion("ubuntu-base", "ami-000003", "2024-01-03T00:00:00.000Z")
+	ec2c.AddImageVersion("ubuntu-base", "ami-000002", "2024-01-02T00:00:00.000Z")
+
+	sel := &Selector{Tags: map[string]string{"Name": "ubuntu-base"}}
+
+	_, err := FindBySelector(ec2c, sel)
+	assert.Error(t, err)
+
+	sel.Latest = true
+	img, err := FindBySelector(ec2c, sel)
+	assert.NoError(t, err)
+	assert.Equal(t, "ami-000003", *img.ImageID)
+	assert.Equal(t, "odin", *img.DeployWithTag)
+}
+
+func Test_FindBySelector_NotFound(t *testing.T) {
+	ec2c := &mocks.EC2Client{}
+	ec2c.AddImageVersion("ubuntu-base", "ami-000001", "2024-01-01T00:00:00.000Z")
+	ec2c.DescribeImagesResp.Resp.Images = nil
+
+	_, err := FindBySelector(ec2c, &Selector{Tags: map[string]string{"Name": "ubuntu-base"}, Latest: true})
+	assert.Error(t, err)
+}
+
+func Test_FindPinnedBySelector(t *testing.T) {
+	ec2c := &mocks.EC2Client{}
+	ec2c.AddImageVersion("ubuntu-base", "ami-000001", "2024-01-01T00:00:00.000Z")
+	ec2c.AddImageVersion("ubuntu-base", "ami-000002", "2024-01-02T00:00:00.000Z")
+	ec2c.AddImageVersion("other", "ami-000003", "2024-01-03T00:00:00.000Z")
+
+	sel := &Selector{Tags: map[string]string{"Name": "ubuntu-*"}, Latest: true}
+
+	// The pinned image, not the latest
+	img, err := FindPinnedBySelector(ec2c, sel, to.Strp("ami-000001"))
+	assert.NoError(t, err)
+	assert.Equal(t, "ami-000001", *img.ImageID)
+
+	_, err = FindPinnedBySelector(ec2c, sel, to.Strp("ami-000003"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "AMI ami-000003 does not match the selector")
+	}
+}
@@ -2,11 +2,14 @@ package asg
 
 import (
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/coinbase/odin/aws"
 	"github.com/coinbase/odin/aws/lc"
+	"github.com/coinbase/odin/aws/lt"
 	"github.com/coinbase/step/utils/is"
 	"github.com/coinbase/step/utils/to"
 )
@@ -25,10 +28,15 @@ type ASG struct {
 
 	AutoScalingGroupName    *string
 	LaunchConfigurationName *string
+	LaunchTemplateName      *string
 
 	LoadBalancerNames []*string
 	TargetGroupARNs   []*string
 
+	CreatedTime *time.Time
+
+	SuspendedProcesses []*string
+
 	instances []*autoscaling.Instance
 }
 
@@ -71,6 +79,16 @@ func (s *ASG) ServiceID() *string {
 //////
 
 func newASG(group *autoscaling.Group) *ASG {
+	var launchTemplateName *string
+	if group.LaunchTemplate != nil {
+		launchTemplateName = group.LaunchTemplate.LaunchTemplateName
+	}
+
+	suspended := []*string{}
+	for _, p := range group.SuspendedProcesses {
+		suspended = append(suspended, p.ProcessName)
+	}
+
 	return &ASG{
 		ProjectNameTag: aws.FetchASGTag(group.Tags, to.Strp("ProjectName")),
 		ConfigNameTag:  aws.FetchASGTag(group.Tags, to.Strp("ConfigName")),
@@ -80,6 +98,7 @@ func newASG(group *autoscaling.Group) *ASG {
 
 		AutoScalingGroupName:    group.AutoScalingGroupName,
 		LaunchConfigurationName: group.LaunchConfigurationName,
+		LaunchTemplateName:      launchTemplateName,
 
 		LoadBalancerNames: group.LoadBalancerNames,
 		TargetGroupARNs:   group.TargetGroupARNs,
@@ -88,6 +107,10 @@ func newASG(group *autoscaling.Group) *ASG {
 		MinSize:         group.MinSize,
 		MaxSize:         group.MaxSize,
 
+		CreatedTime: group.CreatedTime,
+
+		SuspendedProcesses: suspended,
+
 		instances: group.Instances,
 	}
 }
@@ -103,13 +126,7 @@ func GetInstances(asgc aws.ASGAPI, asgName *string) (aws.Instances, *ASG, error)
 		return nil, nil, err
 	}
 
-	instances := aws.Instances{}
-
-	for _, i := range group.instances {
-		instances.AddASGInstance(i)
-	}
-
-	return instances, group, nil
+	return group.Instances(), group, nil
 }
 
 func findByName(asgc aws.ASGAPI, asgName *string) (*ASG, error) {
@@ -138,6 +155,47 @@ func findByName(asgc aws.ASGAPI, asgName *string) (*ASG, error) {
 	}
 }
 
+// Instances returns the instances attached to the ASG when it was fetched
+func (s *ASG) Instances() aws.Instances {
+	instances := aws.Instances{}
+	for _, i := range s.instances {
+		instances.AddASGInstance(i)
+	}
+	return instances
+}
+
+// LifecycleWaitingIDs returns the IDs of instances paused by a launching lifecycle hook
+// These are counted as unhealthy by Instances until the hook completes
+func (s *ASG) LifecycleWaitingIDs() []string {
+	ids := []string{}
+	for _, i := range s.instances {
+		if i == nil || i.InstanceId == nil || i.LifecycleState == nil {
+			continue
+		}
+
+		switch *i.LifecycleState {
+		case autoscaling.LifecycleStatePendingWait, autoscaling.LifecycleStatePendingProceed:
+			ids = append(ids, *i.InstanceId)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Activities returns the most recent scaling activities of the ASG, newest first
+func (s *ASG) Activities(asgc aws.ASGAPI, maxRecords int64) ([]*autoscaling.Activity, error) {
+	output, err := asgc.DescribeScalingActivities(&autoscaling.DescribeScalingActivitiesInput{
+		AutoScalingGroupName: s.ServiceID(),
+		MaxRecords:           to.Int64p(maxRecords),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output.Activities, nil
+}
+
 //////////
 // Find
 //////////
@@ -170,7 +228,7 @@ func ForProjectConfigNotReleaseIDServiceMap(asgc aws.ASGAPI, projectName *string
 
 // ForProjectConfigNOTReleaseID returns all ASGs not with the release ID
 func ForProjectConfigNOTReleaseID(asgc aws.ASGAPI, projectName *string, configName *string, releaseID *string) ([]*ASG, error) {
-	all, err := forProjectConfig(asgc, projectName, configName)
+	all, err := ForProjectConfig(asgc, projectName, configName)
 	if err != nil {
 		return nil, err
 	}
@@ -187,7 +245,7 @@ func ForProjectConfigNOTReleaseID(asgc aws.ASGAPI, projectName *string, configNa
 
 // ForProjectConfigReleaseID returns all ASGs with a release ID
 func ForProjectConfigReleaseID(asgc aws.ASGAPI, projectName *string, configName *string, releaseID *string) ([]*ASG, error) {
-	all, err := forProjectConfig(asgc, projectName, configName)
+	all, err := ForProjectConfig(asgc, projectName, configName)
 	if err != nil {
 		return nil, err
 	}
@@ -202,7 +260,13 @@ func ForProjectConfigReleaseID(asgc aws.ASGAPI, projectName *string, configName
 	return asgs, nil
 }
 
-func forProjectConfig(asgc aws.ASGAPI, projectName *string, configName *string) ([]*ASG, error) {
+// All returns every ASG in the account
+func All(asgc aws.ASGAPI) ([]*ASG, error) {
+	return findInAws(asgc, &autoscaling.DescribeAutoScalingGroupsInput{})
+}
+
+// ForProjectConfig returns all ASGs for the project config
+func ForProjectConfig(asgc aws.ASGAPI, projectName *string, configName *string) ([]*ASG, error) {
 	all, err := findInAws(asgc, &autoscaling.DescribeAutoScalingGroupsInput{})
 	if err != nil {
 		return nil, err
@@ -244,10 +308,113 @@ func findInAws(asgc aws.ASGAPI, params *autoscaling.DescribeAutoScalingGroupsInp
 	return allGroups, nil
 }
 
+//////////
+// Scaling Processes
+//////////
+
+// ScaleInProcesses are the processes that can terminate instances of an ASG that is not being deployed to
+var ScaleInProcesses = []*string{
+	to.Strp("AlarmNotification"),
+	to.Strp("ScheduledActions"),
+	to.Strp("AZRebalance"),
+}
+
+// NotSuspended returns the processes the ASG is still running
+func (s *ASG) NotSuspended(processes []*string) []*string {
+	suspended := map[string]bool{}
+	for _, p := range s.SuspendedProcesses {
+		suspended[to.Strs(p)] = true
+	}
+
+	running := []*string{}
+	for _, p := range processes {
+		if !suspended[to.Strs(p)] {
+			running = append(running, p)
+		}
+	}
+
+	return running
+}
+
+// SuspendProcesses stops the ASG running the scaling processes
+func (s *ASG) SuspendProcesses(asgc aws.ASGAPI, processes []*string) error {
+	_, err := asgc.SuspendProcesses(&autoscaling.ScalingProcessQuery{
+		AutoScalingGroupName: s.ServiceID(),
+		ScalingProcesses:     processes,
+	})
+	return err
+}
+
+// ResumeProcesses lets the ASG run the scaling processes again
+func (s *ASG) ResumeProcesses(asgc aws.ASGAPI, processes []*string) error {
+	_, err := asgc.ResumeProcesses(&autoscaling.ScalingProcessQuery{
+		AutoScalingGroupName: s.ServiceID(),
+		ScalingProcesses:     processes,
+	})
+	return err
+}
+
 //////////
 // Destruction
 //////////
 
+// Max number of instances SetInstanceProtection accepts
+const maxInstanceProtectionIDs = 50
+
+// Drain scales the ASG to zero so its instances terminate through their lifecycle hooks
+// It returns true once the ASG has no instances left and can be deleted
+func (s *ASG) Drain(asgc aws.ASGAPI) (bool, error) {
+	if len(s.instances) == 0 {
+		return true, nil
+	}
+
+	// Stop alarms and schedules scaling the ASG back up
+	if err := s.SuspendProcesses(asgc, ScaleInProcesses); err != nil {
+		return false, err
+	}
+
+	// Instances protected from scale in would never terminate
+	protected := []*string{}
+	for _, i := range s.instances {
+		if i.ProtectedFromScaleIn != nil && *i.ProtectedFromScaleIn {
+			protected = append(protected, i.InstanceId)
+		}
+	}
+
+	for len(protected) > 0 {
+		n := len(protected)
+		if n > maxInstanceProtectionIDs {
+			n = maxInstanceProtectionIDs
+		}
+
+		_, err := asgc.SetInstanceProtection(&autoscaling.SetInstanceProtectionInput{
+			AutoScalingGroupName: s.ServiceID(),
+			InstanceIds:          protected[:n],
+			ProtectedFromScaleIn: to.Boolp(false),
+		})
+
+		if err != nil {
+			return false, err
+		}
+
+		protected = protected[n:]
+	}
+
+	if s.MinSize == nil || *s.MinSize != 0 || s.DesiredCapacity == nil || *s.DesiredCapacity != 0 {
+		_, err := asgc.UpdateAutoScalingGroup(&autoscaling.UpdateAutoScalingGroupInput{
+			AutoScalingGroupName: s.ServiceID(),
+			MinSize:              to.Int64p(0),
+			DesiredCapacity:      to.Int64p(0),
+		})
+
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return false, nil
+}
+
 func (s *ASG) Detach(asgc aws.ASGAPI) error {
 	if len(s.LoadBalancerNames) > 0 {
 		_, err := asgc.DetachLoadBalancers(&autoscaling.DetachLoadBalancersInput{
@@ -337,8 +504,8 @@ func (s *ASG) attachedClassicLBs(asgc aws.ASGAPI) ([]string, error) {
 	return lbs, nil
 }
 
-// Teardown deletes the ASG with launch config and alarms
-func (s *ASG) Teardown(asgc aws.ASGAPI, cwc aws.CWAPI) error {
+// Teardown deletes the ASG with launch config or launch template and alarms
+func (s *ASG) Teardown(asgc aws.ASGAPI, cwc aws.CWAPI, ec2c aws.EC2API) error {
 	// Delete Alarms
 	alarms, err := s.alarmNames(asgc)
 	if err != nil {
@@ -354,7 +521,11 @@ func (s *ASG) Teardown(asgc aws.ASGAPI, cwc aws.CWAPI) error {
 		return err
 	}
 
-	// Delete Launch Config as well
+	// Delete Launch Template or Launch Config as well
+	if s.LaunchTemplateName != nil {
+		return lt.Teardown(ec2c, s.LaunchTemplateName)
+	}
+
 	if err := lc.Teardown(asgc, s.LaunchConfigurationName); err != nil {
 		return err
 	}
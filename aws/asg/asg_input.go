@@ -48,13 +48,15 @@ func (s *Input) SetDefaults() {
 		s.HealthCheckGracePeriod = to.Int64p(300)
 	}
 
-	if s.LaunchConfigurationName == nil {
+	if s.LaunchConfigurationName == nil && s.LaunchTemplate == nil {
 		s.LaunchConfigurationName = s.AutoScalingGroupName // Makes the name the same
 	}
 
-	s.HealthCheckType = to.Strp("EC2")
-	if len(s.LoadBalancerNames) > 0 || len(s.TargetGroupARNs) > 0 {
-		s.HealthCheckType = to.Strp("ELB") // If there are any ELBs set the health check to that
+	if s.HealthCheckType == nil {
+		s.HealthCheckType = to.Strp("EC2")
+		if len(s.LoadBalancerNames) > 0 || len(s.TargetGroupARNs) > 0 {
+			s.HealthCheckType = to.Strp("ELB") // If there are any ELBs set the health check to that
+		}
 	}
 
 	if len(s.TerminationPolicies) == 0 {
@@ -62,8 +64,8 @@ func (s *Input) SetDefaults() {
 	}
 }
 
-// AddTag adds a tag to the input
-func (s *Input) AddTag(key string, value *string) {
+// AddTag adds a tag to the input, propagateAtLaunch copies it to the instances
+func (s *Input) AddTag(key string, value *string, propagateAtLaunch bool) {
 	if s.Tags == nil {
 		s.Tags = []*autoscaling.Tag{}
 	}
@@ -71,12 +73,13 @@ func (s *Input) AddTag(key string, value *string) {
 	for _, tag := range s.Tags {
 		if *tag.Key == key {
 			tag.Value = value
+			tag.PropagateAtLaunch = to.Boolp(propagateAtLaunch)
 			return // Found the tag key already
 		}
 	}
 
 	// Add new Tag
-	s.Tags = append(s.Tags, &autoscaling.Tag{Key: &key, Value: value, PropagateAtLaunch: to.Boolp(true)})
+	s.Tags = append(s.Tags, &autoscaling.Tag{Key: &key, Value: value, PropagateAtLaunch: to.Boolp(propagateAtLaunch)})
 }
 
 // ToASG returns ASG object
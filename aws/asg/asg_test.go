@@ -88,17 +88,71 @@ func Test_ForProjectConfigReleaseID(t *testing.T) {
 }
 
 func Test_Teardown(t *testing.T) {
-	// func (s *ASG) Teardown(asgc aws.ASGAPI, cwc aws.CWAPI) error {
+	// func (s *ASG) Teardown(asgc aws.ASGAPI, cwc aws.CWAPI, ec2c aws.EC2API) error {
 	asgc := &mocks.ASGClient{}
 	cwc := &mocks.CWClient{}
+	ec2c := &mocks.EC2Client{}
 
 	asgc.AddPreviousRuntimeResources("project", "config", "service1", "not_release")
 	asgs, err := ForProjectConfigNOTReleaseID(asgc, to.Strp("project"), to.Strp("config"), to.Strp("release"))
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(asgs))
 
-	err = asgs[0].Teardown(asgc, cwc)
+	err = asgs[0].Teardown(asgc, cwc, ec2c)
 	assert.NoError(t, err)
+	assert.Equal(t, 0, len(ec2c.DeletedLaunchTemplates))
+}
+
+func Test_Teardown_LaunchTemplate(t *testing.T) {
+	asgc := &mocks.ASGClient{}
+	cwc := &mocks.CWClient{}
+	ec2c := &mocks.EC2Client{}
+
+	group := mocks.MakeMockASG("project-config-service1-not_release", "project", "config", "service1", "not_release")
+	group.LaunchTemplate = &autoscaling.LaunchTemplateSpecification{LaunchTemplateName: group.AutoScalingGroupName}
+	asgc.AddASG(group)
+
+	asgs, err := ForProjectConfigNOTReleaseID(asgc, to.Strp("project"), to.Strp("config"), to.Strp("release"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(asgs))
+
+	err = asgs[0].Teardown(asgc, cwc, ec2c)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"project-config-service1-not_release"}, ec2c.DeletedLaunchTemplates)
+	assert.Equal(t, 0, len(asgc.DeletedLaunchConfigurations))
+}
+
+func Test_Drain(t *testing.T) {
+	asgc := &mocks.ASGClient{}
+
+	group := mocks.MakeMockASG("project-config-service1-not_release", "project", "config", "service1", "not_release")
+	group.Instances[0].ProtectedFromScaleIn = to.Boolp(true)
+	asgc.AddASG(group)
+
+	asgs, err := ForProjectConfigNOTReleaseID(asgc, to.Strp("project"), to.Strp("config"), to.Strp("release"))
+	assert.NoError(t, err)
+
+	drained, err := asgs[0].Drain(asgc)
+	assert.NoError(t, err)
+	assert.False(t, drained)
+
+	assert.Equal(t, int64(0), *asgc.UpdateAutoScalingGroupLastInput.MinSize)
+	assert.Equal(t, int64(0), *asgc.UpdateAutoScalingGroupLastInput.DesiredCapacity)
+	assert.Equal(t, []string{"InstanceId1"}, asgc.UnprotectedIDs)
+	assert.Equal(t, 3, len(asgc.SuspendedProcesses["project-config-service1-not_release"]))
+
+	// No instances left
+	asgc = &mocks.ASGClient{}
+	group.Instances = nil
+	asgc.AddASG(group)
+
+	asgs, err = ForProjectConfigNOTReleaseID(asgc, to.Strp("project"), to.Strp("config"), to.Strp("release"))
+	assert.NoError(t, err)
+
+	drained, err = asgs[0].Drain(asgc)
+	assert.NoError(t, err)
+	assert.True(t, drained)
+	assert.Nil(t, asgc.UpdateAutoScalingGroupLastInput)
 }
 
 func Test_AttachedLBs(t *testing.T) {
@@ -22,6 +22,8 @@ import (
 	"github.com/aws/aws-sdk-go/service/sfn/sfniface"
 	"github.com/aws/aws-sdk-go/service/sns"
 	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	ar "github.com/coinbase/step/aws"
 )
 
@@ -178,6 +180,9 @@ type IAMAPI iamiface.IAMAPI
 // SNSAPI aws API
 type SNSAPI snsiface.SNSAPI
 
+// SQSAPI aws API
+type SQSAPI sqsiface.SQSAPI
+
 // SFNAPI aws API
 type SFNAPI sfniface.SFNAPI
 
@@ -194,6 +199,7 @@ type Clients interface {
 	CWClient(region *string, accountID *string, role *string) CWAPI
 	IAMClient(region *string, accountID *string, role *string) IAMAPI
 	SNSClient(region *string, accountID *string, role *string) SNSAPI
+	SQSClient(region *string, accountID *string, role *string) SQSAPI
 	SFNClient(region *string, accountID *string, role *string) SFNAPI
 	DynamoDBClient(region *string, accountID *string, role *string) DynamoDBAPI
 }
@@ -243,6 +249,11 @@ func (awsc *ClientsStr) SNSClient(region *string, accountID *string, role *strin
 	return sns.New(awsc.Session(), awsc.Config(region, accountID, role))
 }
 
+// SQSClient returns client for region account and role
+func (awsc *ClientsStr) SQSClient(region *string, accountID *string, role *string) SQSAPI {
+	return sqs.New(awsc.Session(), awsc.Config(region, accountID, role))
+}
+
 // SFNClient returns client for region account and role
 func (awsc *ClientsStr) SFNClient(region *string, accountID *string, role *string) SFNAPI {
 	return sfn.New(awsc.Session(), awsc.Config(region, accountID, role))
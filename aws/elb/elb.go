@@ -2,6 +2,7 @@ package elb
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	aws_elb "github.com/aws/aws-sdk-go/service/elb"
@@ -96,7 +97,7 @@ func instanceStates(elbc aws.ELBAPI, name *string, instances []string) ([]*aws_e
 // Find
 ///////
 
-// FindAll returns ELBs with names
+// FindAll returns ELBs with names, or classic load balancer ARNs
 func FindAll(elbc aws.ELBAPI, names []*string) ([]*LoadBalancer, error) {
 	elbs := []*LoadBalancer{}
 	for _, name := range names {
@@ -110,7 +111,8 @@ func FindAll(elbc aws.ELBAPI, names []*string) ([]*LoadBalancer, error) {
 	return elbs, nil
 }
 
-func find(elbc aws.ELBAPI, name *string) (*LoadBalancer, error) {
+func find(elbc aws.ELBAPI, nameOrARN *string) (*LoadBalancer, error) {
+	name := nameFromARN(nameOrARN)
 
 	elbDesc, err := findAwsByName(elbc, name)
 
@@ -132,6 +134,21 @@ func find(elbc aws.ELBAPI, name *string) (*LoadBalancer, error) {
 	}, nil
 }
 
+// nameFromARN returns the name of a classic load balancer ARN
+// e.g. arn:aws:elasticloadbalancing:us-east-1:000000000000:loadbalancer/name, otherwise the name
+func nameFromARN(nameOrARN *string) *string {
+	if !strings.HasPrefix(*nameOrARN, "arn:") {
+		return nameOrARN
+	}
+
+	parts := strings.SplitN(*nameOrARN, ":loadbalancer/", 2)
+	if len(parts) != 2 {
+		return nameOrARN
+	}
+
+	return &parts[1]
+}
+
 func findAwsByName(elbc aws.ELBAPI, name *string) (*aws_elb.LoadBalancerDescription, error) {
 	elbsOutput, err := elbc.DescribeLoadBalancers(&aws_elb.DescribeLoadBalancersInput{
 		LoadBalancerNames: []*string{name},
@@ -36,6 +36,17 @@ func Test_FindAll(t *testing.T) {
 	assert.Equal(t, 2, len(elbs))
 }
 
+func Test_FindAll_ARN(t *testing.T) {
+	elbc := &mocks.ELBClient{}
+	elbc.AddELB("asd", "project", "config", "service")
+
+	elbs, err := FindAll(elbc, []*string{to.Strp("arn:aws:elasticloadbalancing:us-east-1:000000000000:loadbalancer/asd")})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(elbs))
+	assert.Equal(t, "asd", *elbs[0].LoadBalancerName)
+}
+
 func Test_createDescribeInstanceHealthInput(t *testing.T) {
 	name := ""
 
@@ -1,7 +1,10 @@
 package aws
 
 import (
+	"encoding/base64"
+
 	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/elb"
 	"github.com/aws/aws-sdk-go/service/elbv2"
 )
@@ -138,3 +141,27 @@ func stateCompare(s1 string, s2 string) string {
 	// Otherwise Unhealthy
 	return unhealthy
 }
+
+// ConsoleOutput returns the decoded console output of an instance
+// AWS keeps the output of terminated instances for a short time after termination
+func ConsoleOutput(ec2c EC2API, instanceID *string) (*string, error) {
+	output, err := ec2c.GetConsoleOutput(&ec2.GetConsoleOutputInput{
+		InstanceId: instanceID,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if output.Output == nil {
+		return nil, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(*output.Output)
+	if err != nil {
+		return nil, err
+	}
+
+	str := string(decoded)
+	return &str, nil
+}
@@ -0,0 +1,103 @@
+package instancetype
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/utils/to"
+)
+
+// InstanceType is the metadata of an EC2 instance type odin validates and launches with
+type InstanceType struct {
+	InstanceType        *string   `json:"instance_type,omitempty"`
+	EbsOptimizedSupport *string   `json:"ebs_optimized_support,omitempty"` // unsupported | supported | default
+	Architectures       []*string `json:"architectures,omitempty"`         // i386 | x86_64 | arm64 | x86_64_mac
+	VCpus               *int64    `json:"vcpus,omitempty"`
+	MemoryMiB           *int64    `json:"memory_mib,omitempty"`
+	VirtualizationTypes []*string `json:"virtualization_types,omitempty"` // hvm | paravirtual
+
+	// Burstable types (T family) accept a credit specification
+	BurstablePerformanceSupported *bool `json:"burstable_performance_supported,omitempty"`
+
+	FetchedAt *time.Time `json:"fetched_at,omitempty"`
+}
+
+// EbsOptimized returns true if the instance type is EBS optimized at no extra cost
+// Types that only support it for an hourly fee are not EBS optimized by default
+func (it *InstanceType) EbsOptimized() bool {
+	return to.Strs(it.EbsOptimizedSupport) == ec2.EbsOptimizedSupportDefault
+}
+
+// SupportsArchitecture returns true if instances of the type can run images for the architecture
+func (it *InstanceType) SupportsArchitecture(architecture string) bool {
+	return contains(it.Architectures, architecture)
+}
+
+// SupportsVirtualizationType returns true if instances of the type can run images with the virtualization type
+func (it *InstanceType) SupportsVirtualizationType(virtualizationType string) bool {
+	return contains(it.VirtualizationTypes, virtualizationType)
+}
+
+// Find returns the instance type from the EC2 API, or an error if it does not exist in the region
+func Find(ec2c aws.EC2API, name *string) (*InstanceType, error) {
+	if name == nil {
+		return nil, fmt.Errorf("InstanceType nil")
+	}
+
+	output, err := ec2c.DescribeInstanceTypes(&ec2.DescribeInstanceTypesInput{
+		InstanceTypes: []*string{name},
+	})
+
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "InvalidInstanceType" {
+			return nil, fmt.Errorf("InstanceType %v does not exist in this region", *name)
+		}
+		return nil, err
+	}
+
+	if len(output.InstanceTypes) != 1 || output.InstanceTypes[0] == nil {
+		return nil, fmt.Errorf("InstanceType %v does not exist in this region", *name)
+	}
+
+	return fromInstanceTypeInfo(output.InstanceTypes[0]), nil
+}
+
+func fromInstanceTypeInfo(info *ec2.InstanceTypeInfo) *InstanceType {
+	it := &InstanceType{
+		InstanceType:        info.InstanceType,
+		VirtualizationTypes: info.SupportedVirtualizationTypes,
+		FetchedAt:           to.Timep(time.Now()),
+
+		BurstablePerformanceSupported: info.BurstablePerformanceSupported,
+	}
+
+	if info.EbsInfo != nil {
+		it.EbsOptimizedSupport = info.EbsInfo.EbsOptimizedSupport
+	}
+
+	if info.ProcessorInfo != nil {
+		it.Architectures = info.ProcessorInfo.SupportedArchitectures
+	}
+
+	if info.VCpuInfo != nil {
+		it.VCpus = info.VCpuInfo.DefaultVCpus
+	}
+
+	if info.MemoryInfo != nil {
+		it.MemoryMiB = info.MemoryInfo.SizeInMiB
+	}
+
+	return it
+}
+
+func contains(strs []*string, s string) bool {
+	for _, str := range strs {
+		if str != nil && *str == s {
+			return true
+		}
+	}
+	return false
+}
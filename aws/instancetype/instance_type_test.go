@@ -0,0 +1,34 @@
+package instancetype
+
+import (
+	"testing"
+
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Find(t *testing.T) {
+	ec2c := &mocks.EC2Client{}
+	ec2c.AddInstanceType("m6i.large", "x86_64", "default")
+	ec2c.AddInstanceType("m3.large", "x86_64", "supported")
+
+	it, err := Find(ec2c, to.Strp("m6i.large"))
+	assert.NoError(t, err)
+	assert.Equal(t, "m6i.large", *it.InstanceType)
+	assert.True(t, it.EbsOptimized())
+	assert.True(t, it.SupportsArchitecture("x86_64"))
+	assert.False(t, it.SupportsArchitecture("arm64"))
+	assert.True(t, it.SupportsVirtualizationType("hvm"))
+	assert.Equal(t, int64(2), *it.VCpus)
+	assert.Equal(t, int64(2048), *it.MemoryMiB)
+
+	// Optional for a fee is not EBS optimized by default
+	it, err = Find(ec2c, to.Strp("m3.large"))
+	assert.NoError(t, err)
+	assert.False(t, it.EbsOptimized())
+
+	_, err = Find(ec2c, to.Strp("x9.huge"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "does not exist")
+}
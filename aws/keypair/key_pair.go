@@ -0,0 +1,47 @@
+package keypair
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/utils/to"
+)
+
+// KeyPair struct
+type KeyPair struct {
+	KeyName       *string
+	KeyPairID     *string
+	DeployWithTag *string
+}
+
+// Find returns the key pair with the name
+func Find(ec2c aws.EC2API, name *string) (*KeyPair, error) {
+	if name == nil {
+		return nil, fmt.Errorf("KeyPair name nil")
+	}
+
+	output, err := ec2c.DescribeKeyPairs(&ec2.DescribeKeyPairsInput{
+		KeyNames: []*string{name},
+	})
+
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "InvalidKeyPair.NotFound" {
+			return nil, fmt.Errorf("KeyPair %v Not Found", *name)
+		}
+		return nil, err
+	}
+
+	if len(output.KeyPairs) != 1 || output.KeyPairs[0] == nil {
+		return nil, fmt.Errorf("KeyPair %v Not Found", *name)
+	}
+
+	kp := output.KeyPairs[0]
+
+	return &KeyPair{
+		KeyName:       kp.KeyName,
+		KeyPairID:     kp.KeyPairId,
+		DeployWithTag: aws.FetchEc2Tag(kp.Tags, to.Strp("DeployWith")),
+	}, nil
+}
@@ -0,0 +1,23 @@
+package keypair
+
+import (
+	"testing"
+
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Find(t *testing.T) {
+	ec2c := &mocks.EC2Client{}
+
+	_, err := Find(ec2c, to.Strp("deploy"))
+	assert.Error(t, err)
+
+	ec2c.AddKeyPair("deploy", "odin")
+
+	kp, err := Find(ec2c, to.Strp("deploy"))
+	assert.NoError(t, err)
+	assert.Equal(t, "deploy", *kp.KeyName)
+	assert.Equal(t, "odin", *kp.DeployWithTag)
+}
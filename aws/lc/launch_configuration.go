@@ -1,9 +1,11 @@
 package lc
 
 import (
-	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"strings"
 
+	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/utils/to"
 )
 
 // Teardown deleted launch configuration
@@ -18,3 +20,27 @@ func Teardown(asgc aws.ASGAPI, name *string) error {
 
 	return nil
 }
+
+// FindByPrefix returns all launch configurations with a name starting with prefix
+func FindByPrefix(asgc aws.ASGAPI, prefix string) ([]*autoscaling.LaunchConfiguration, error) {
+	lcs := []*autoscaling.LaunchConfiguration{}
+
+	pagefn := func(page *autoscaling.DescribeLaunchConfigurationsOutput, lastPage bool) bool {
+		for _, lc := range page.LaunchConfigurations {
+			if lc.LaunchConfigurationName != nil && strings.HasPrefix(*lc.LaunchConfigurationName, prefix) {
+				lcs = append(lcs, lc)
+			}
+		}
+		return !lastPage
+	}
+
+	err := asgc.DescribeLaunchConfigurationsPages(&autoscaling.DescribeLaunchConfigurationsInput{
+		MaxRecords: to.Int64p(100),
+	}, pagefn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return lcs, nil
+}
@@ -6,44 +6,6 @@ import (
 	"github.com/coinbase/step/utils/to"
 )
 
-var ebsOptimizedInstances = map[string]bool{
-	"c4.large":    true,
-	"c4.xlarge":   true,
-	"c4.2xlarge":  true,
-	"c4.4xlarge":  true,
-	"c4.8xlarge":  true,
-	"c5.large":    true,
-	"c5.xlarge":   true,
-	"c5.2xlarge":  true,
-	"c5.4xlarge":  true,
-	"c5.9xlarge":  true,
-	"c5.18xlarge": true,
-	"i3.large":    true,
-	"i3.xlarge":   true,
-	"i3.2xlarge":  true,
-	"i3.4xlarge":  true,
-	"i3.8xlarge":  true,
-	"i3.16xlarge": true,
-	"m4.large":    true,
-	"m4.xlarge":   true,
-	"m4.2xlarge":  true,
-	"m4.4xlarge":  true,
-	"m4.10xlarge": true,
-	"m4.16xlarge": true,
-	"m5.large":    true,
-	"m5.xlarge":   true,
-	"m5.2xlarge":  true,
-	"m5.4xlarge":  true,
-	"m5.12xlarge": true,
-	"m5.24xlarge": true,
-	"r4.large":    true,
-	"r4.xlarge":   true,
-	"r4.2xlarge":  true,
-	"r4.4xlarge":  true,
-	"r4.8xlarge":  true,
-	"r4.16xlarge": true,
-}
-
 // LaunchConfigInput input struct
 type LaunchConfigInput struct {
 	*autoscaling.CreateLaunchConfigurationInput
@@ -103,8 +65,8 @@ func (s *LaunchConfigInput) SetDefaults() {
 		s.InstanceMonitoring = &autoscaling.InstanceMonitoring{Enabled: to.Boolp(false)}
 	}
 
+	// Whether the instance type is EBS optimized comes from its metadata, see instancetype.InstanceType
 	if s.EbsOptimized == nil {
-		opt := ebsOptimizedInstances[*s.InstanceType]
-		s.EbsOptimized = to.Boolp(opt)
+		s.EbsOptimized = to.Boolp(false)
 	}
 }
@@ -0,0 +1,46 @@
+package lt
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/utils/to"
+)
+
+// Teardown deletes the launch template
+func Teardown(ec2c aws.EC2API, name *string) error {
+	_, err := ec2c.DeleteLaunchTemplate(&ec2.DeleteLaunchTemplateInput{
+		LaunchTemplateName: name,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindByPrefix returns all launch templates with a name starting with prefix
+func FindByPrefix(ec2c aws.EC2API, prefix string) ([]*ec2.LaunchTemplate, error) {
+	lts := []*ec2.LaunchTemplate{}
+
+	pagefn := func(page *ec2.DescribeLaunchTemplatesOutput, lastPage bool) bool {
+		for _, lt := range page.LaunchTemplates {
+			if lt.LaunchTemplateName != nil && strings.HasPrefix(*lt.LaunchTemplateName, prefix) {
+				lts = append(lts, lt)
+			}
+		}
+		return !lastPage
+	}
+
+	err := ec2c.DescribeLaunchTemplatesPages(&ec2.DescribeLaunchTemplatesInput{
+		MaxResults: to.Int64p(200),
+	}, pagefn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return lts, nil
+}
@@ -0,0 +1,121 @@
+package lt
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/utils/to"
+)
+
+// LaunchTemplateInput input struct
+type LaunchTemplateInput struct {
+	*ec2.CreateLaunchTemplateInput
+}
+
+// Create tries to create the launch template
+func (s *LaunchTemplateInput) Create(ec2c aws.EC2API) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	_, err := ec2c.CreateLaunchTemplate(s.CreateLaunchTemplateInput)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FromLaunchConfigInput returns a launch template with the same name and instance settings as the launch configuration,
+// so a service can be built the same way whichever it uses
+func FromLaunchConfigInput(lci *autoscaling.CreateLaunchConfigurationInput) *LaunchTemplateInput {
+	data := &ec2.RequestLaunchTemplateData{
+		ImageId:      lci.ImageId,
+		InstanceType: lci.InstanceType,
+		UserData:     lci.UserData,
+		EbsOptimized: lci.EbsOptimized,
+		KeyName:      lci.KeyName,
+	}
+
+	if lci.IamInstanceProfile != nil {
+		data.IamInstanceProfile = &ec2.LaunchTemplateIamInstanceProfileSpecificationRequest{}
+		if strings.HasPrefix(*lci.IamInstanceProfile, "arn:") {
+			data.IamInstanceProfile.Arn = lci.IamInstanceProfile
+		} else {
+			data.IamInstanceProfile.Name = lci.IamInstanceProfile
+		}
+	}
+
+	if lci.InstanceMonitoring != nil {
+		data.Monitoring = &ec2.LaunchTemplatesMonitoringRequest{Enabled: lci.InstanceMonitoring.Enabled}
+	}
+
+	// A public IP can only be requested on a network interface, which then must hold the security groups
+	if lci.AssociatePublicIpAddress != nil {
+		data.NetworkInterfaces = []*ec2.LaunchTemplateInstanceNetworkInterfaceSpecificationRequest{
+			&ec2.LaunchTemplateInstanceNetworkInterfaceSpecificationRequest{
+				DeviceIndex:              to.Int64p(0),
+				AssociatePublicIpAddress: lci.AssociatePublicIpAddress,
+				Groups:                   lci.SecurityGroups,
+			},
+		}
+	} else {
+		data.SecurityGroupIds = lci.SecurityGroups
+	}
+
+	if lci.SpotPrice != nil {
+		data.InstanceMarketOptions = &ec2.LaunchTemplateInstanceMarketOptionsRequest{
+			MarketType:  to.Strp("spot"),
+			SpotOptions: &ec2.LaunchTemplateSpotMarketOptionsRequest{MaxPrice: lci.SpotPrice},
+		}
+	}
+
+	if lci.MetadataOptions != nil {
+		data.MetadataOptions = &ec2.LaunchTemplateInstanceMetadataOptionsRequest{
+			HttpTokens:              lci.MetadataOptions.HttpTokens,
+			HttpPutResponseHopLimit: lci.MetadataOptions.HttpPutResponseHopLimit,
+			HttpEndpoint:            lci.MetadataOptions.HttpEndpoint,
+		}
+	}
+
+	if lci.PlacementTenancy != nil {
+		data.Placement = &ec2.LaunchTemplatePlacementRequest{Tenancy: lci.PlacementTenancy}
+	}
+
+	for _, bdm := range lci.BlockDeviceMappings {
+		data.BlockDeviceMappings = append(data.BlockDeviceMappings, toLaunchTemplateBlockDeviceMapping(bdm))
+	}
+
+	return &LaunchTemplateInput{&ec2.CreateLaunchTemplateInput{
+		LaunchTemplateName: lci.LaunchConfigurationName,
+		LaunchTemplateData: data,
+	}}
+}
+
+func toLaunchTemplateBlockDeviceMapping(bdm *autoscaling.BlockDeviceMapping) *ec2.LaunchTemplateBlockDeviceMappingRequest {
+	mapping := &ec2.LaunchTemplateBlockDeviceMappingRequest{
+		DeviceName:  bdm.DeviceName,
+		VirtualName: bdm.VirtualName,
+	}
+
+	if bdm.NoDevice != nil && *bdm.NoDevice {
+		mapping.NoDevice = to.Strp("")
+	}
+
+	if bdm.Ebs != nil {
+		mapping.Ebs = &ec2.LaunchTemplateEbsBlockDeviceRequest{
+			VolumeSize:          bdm.Ebs.VolumeSize,
+			VolumeType:          bdm.Ebs.VolumeType,
+			Iops:                bdm.Ebs.Iops,
+			Throughput:          bdm.Ebs.Throughput,
+			SnapshotId:          bdm.Ebs.SnapshotId,
+			DeleteOnTermination: bdm.Ebs.DeleteOnTermination,
+			Encrypted:           bdm.Ebs.Encrypted,
+		}
+	}
+
+	return mapping
+}
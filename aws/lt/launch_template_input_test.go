@@ -0,0 +1,46 @@
+package lt
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FromLaunchConfigInput(t *testing.T) {
+	lci := &autoscaling.CreateLaunchConfigurationInput{
+		LaunchConfigurationName: to.Strp("project-config-2020-01-02T03-04-05Z-web"),
+		ImageId:                 to.Strp("ami-123456"),
+		InstanceType:            to.Strp("t2.small"),
+		IamInstanceProfile:      to.Strp("arn:aws:iam::000000000000:instance-profile/web-profile"),
+		SecurityGroups:          []*string{to.Strp("sg-1")},
+		SpotPrice:               to.Strp("0.1"),
+		InstanceMonitoring:      &autoscaling.InstanceMonitoring{Enabled: to.Boolp(false)},
+		BlockDeviceMappings: []*autoscaling.BlockDeviceMapping{
+			&autoscaling.BlockDeviceMapping{
+				DeviceName: to.Strp("/dev/xvda"),
+				Ebs:        &autoscaling.Ebs{VolumeSize: to.Int64p(20), VolumeType: to.Strp("gp3")},
+			},
+		},
+	}
+
+	input := FromLaunchConfigInput(lci)
+	assert.NoError(t, input.Validate())
+
+	data := input.LaunchTemplateData
+	assert.Equal(t, "project-config-2020-01-02T03-04-05Z-web", *input.LaunchTemplateName)
+	assert.Equal(t, "ami-123456", *data.ImageId)
+	assert.Equal(t, "arn:aws:iam::000000000000:instance-profile/web-profile", *data.IamInstanceProfile.Arn)
+	assert.Equal(t, []string{"sg-1"}, to.StrSlice(data.SecurityGroupIds))
+	assert.Equal(t, "0.1", *data.InstanceMarketOptions.SpotOptions.MaxPrice)
+	assert.Equal(t, int64(20), *data.BlockDeviceMappings[0].Ebs.VolumeSize)
+	assert.Nil(t, data.NetworkInterfaces)
+
+	// A public IP moves the security groups to the network interface
+	lci.AssociatePublicIpAddress = to.Boolp(true)
+	data = FromLaunchConfigInput(lci).LaunchTemplateData
+	assert.Nil(t, data.SecurityGroupIds)
+	assert.Equal(t, []string{"sg-1"}, to.StrSlice(data.NetworkInterfaces[0].Groups))
+	assert.True(t, *data.NetworkInterfaces[0].AssociatePublicIpAddress)
+}
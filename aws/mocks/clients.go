@@ -7,7 +7,7 @@ import (
 
 // MockClients struct
 type MockClients struct {
-	S3       *mocks.MockS3Client
+	S3       *S3Client
 	ASG      *ASGClient
 	ELB      *ELBClient
 	EC2      *EC2Client
@@ -15,14 +15,15 @@ type MockClients struct {
 	CW       *CWClient
 	IAM      *IAMClient
 	SNS      *SNSClient
-	SFN      *mocks.MockSFNClient
+	SQS      *SQSClient
+	SFN      *SFNClient
 	DynamoDB *mocks.MockDynamoDBClient
 }
 
 // MockAWS mock clients
 func MockAWS() *MockClients {
 	return &MockClients{
-		S3:       &mocks.MockS3Client{},
+		S3:       &S3Client{MockS3Client: &mocks.MockS3Client{}},
 		ASG:      &ASGClient{},
 		ELB:      &ELBClient{},
 		EC2:      &EC2Client{},
@@ -30,7 +31,8 @@ func MockAWS() *MockClients {
 		CW:       &CWClient{},
 		IAM:      &IAMClient{},
 		SNS:      &SNSClient{},
-		SFN:      &mocks.MockSFNClient{},
+		SQS:      &SQSClient{},
+		SFN:      &SFNClient{MockSFNClient: &mocks.MockSFNClient{}},
 		DynamoDB: &mocks.MockDynamoDBClient{},
 	}
 }
@@ -75,6 +77,11 @@ func (a *MockClients) SNSClient(*string, *string, *string) aws.SNSAPI {
 	return a.SNS
 }
 
+// SQSClient returns
+func (a *MockClients) SQSClient(*string, *string, *string) aws.SQSAPI {
+	return a.SQS
+}
+
 // SFNClient returns
 func (a *MockClients) SFNClient(*string, *string, *string) aws.SFNAPI {
 	return a.SFN
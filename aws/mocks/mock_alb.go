@@ -152,8 +152,17 @@ func (m *ALBClient) AddTargetGroup(parameters MockTargetGroup) {
 // DescribeTargetGroups return
 func (m *ALBClient) DescribeTargetGroups(in *elbv2.DescribeTargetGroupsInput) (*elbv2.DescribeTargetGroupsOutput, error) {
 	m.init()
-	lbName := in.Names[0]
-	resp := m.DescribeTargetGroupsResp[*lbName]
+	var resp *DescribeTargetGroupsResponse
+	if len(in.TargetGroupArns) > 0 {
+		for _, r := range m.DescribeTargetGroupsResp {
+			if r.Resp != nil && len(r.Resp.TargetGroups) > 0 && *r.Resp.TargetGroups[0].TargetGroupArn == *in.TargetGroupArns[0] {
+				resp = r
+			}
+		}
+	} else {
+		resp = m.DescribeTargetGroupsResp[*in.Names[0]]
+	}
+
 	if resp == nil {
 		return nil, AWSTargetGroupNotFoundError()
 	}
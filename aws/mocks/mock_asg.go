@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/coinbase/odin/aws"
@@ -26,18 +27,33 @@ type DescribePoliciesResponse struct {
 	Error error
 }
 
+// DescribeScalingActivitiesResponse returns
+type DescribeScalingActivitiesResponse struct {
+	Resp  *autoscaling.DescribeScalingActivitiesOutput
+	Error error
+}
+
 // ASGClient returns
 type ASGClient struct {
 	aws.ASGAPI
 	DescribeAutoScalingGroupsPageResp []DescribeAutoScalingGroupResponse
 	DescribeLaunchConfigurationsResp  map[string]*DescribeLaunchConfigurationsResponse
 	DescribePoliciesResp              map[string]*DescribePoliciesResponse
+	DescribeScalingActivitiesResp     map[string]*DescribeScalingActivitiesResponse
 
 	DescribeLoadBalancerTargetGroupsOutput *autoscaling.DescribeLoadBalancerTargetGroupsOutput
 	DescribeLoadBalancersOutput            *autoscaling.DescribeLoadBalancersOutput
 
 	UpdateAutoScalingGroupLastInput *autoscaling.UpdateAutoScalingGroupInput
 	DetachLoadBalancersError        error
+
+	DeletedAutoScalingGroups    []string
+	DeletedLaunchConfigurations []string
+
+	SuspendedProcesses   map[string][]string
+	ResumedProcesses     map[string][]string
+	ResumeProcessesError error
+	UnprotectedIDs       []string
 }
 
 func (m *ASGClient) init() {
@@ -52,6 +68,18 @@ func (m *ASGClient) init() {
 	if m.DescribePoliciesResp == nil {
 		m.DescribePoliciesResp = map[string]*DescribePoliciesResponse{}
 	}
+
+	if m.DescribeScalingActivitiesResp == nil {
+		m.DescribeScalingActivitiesResp = map[string]*DescribeScalingActivitiesResponse{}
+	}
+
+	if m.SuspendedProcesses == nil {
+		m.SuspendedProcesses = map[string][]string{}
+	}
+
+	if m.ResumedProcesses == nil {
+		m.ResumedProcesses = map[string][]string{}
+	}
 }
 
 // MakeMockASG returns
@@ -182,6 +210,7 @@ func (m *ASGClient) DescribeAutoScalingGroupsPages(input *autoscaling.DescribeAu
 
 // DeleteAutoScalingGroup returns
 func (m *ASGClient) DeleteAutoScalingGroup(input *autoscaling.DeleteAutoScalingGroupInput) (*autoscaling.DeleteAutoScalingGroupOutput, error) {
+	m.DeletedAutoScalingGroups = append(m.DeletedAutoScalingGroups, *input.AutoScalingGroupName)
 	return nil, nil
 }
 
@@ -208,9 +237,46 @@ func (m *ASGClient) CreateLaunchConfiguration(input *autoscaling.CreateLaunchCon
 
 // DeleteLaunchConfiguration returns
 func (m *ASGClient) DeleteLaunchConfiguration(input *autoscaling.DeleteLaunchConfigurationInput) (*autoscaling.DeleteLaunchConfigurationOutput, error) {
+	if input.LaunchConfigurationName != nil {
+		m.DeletedLaunchConfigurations = append(m.DeletedLaunchConfigurations, *input.LaunchConfigurationName)
+	}
 	return nil, nil
 }
 
+// AddLaunchConfiguration returns
+func (m *ASGClient) AddLaunchConfiguration(name string) {
+	m.init()
+	m.DescribeLaunchConfigurationsResp[name] = &DescribeLaunchConfigurationsResponse{
+		Resp: &autoscaling.DescribeLaunchConfigurationsOutput{
+			LaunchConfigurations: []*autoscaling.LaunchConfiguration{
+				&autoscaling.LaunchConfiguration{LaunchConfigurationName: to.Strp(name)},
+			},
+		},
+	}
+}
+
+// DescribeLaunchConfigurationsPages returns all added launch configurations in name order
+func (m *ASGClient) DescribeLaunchConfigurationsPages(in *autoscaling.DescribeLaunchConfigurationsInput, fn func(*autoscaling.DescribeLaunchConfigurationsOutput, bool) bool) error {
+	m.init()
+	names := []string{}
+	for name := range m.DescribeLaunchConfigurationsResp {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lcs := []*autoscaling.LaunchConfiguration{}
+	for _, name := range names {
+		resp := m.DescribeLaunchConfigurationsResp[name]
+		if resp.Error != nil {
+			return resp.Error
+		}
+		lcs = append(lcs, resp.Resp.LaunchConfigurations...)
+	}
+
+	fn(&autoscaling.DescribeLaunchConfigurationsOutput{LaunchConfigurations: lcs}, true)
+	return nil
+}
+
 // DescribePolicies returns
 func (m *ASGClient) DescribePolicies(in *autoscaling.DescribePoliciesInput) (*autoscaling.DescribePoliciesOutput, error) {
 	m.init()
@@ -221,6 +287,34 @@ func (m *ASGClient) DescribePolicies(in *autoscaling.DescribePoliciesInput) (*au
 	return resp.Resp, resp.Error
 }
 
+// AddScalingActivity returns
+func (m *ASGClient) AddScalingActivity(asgName string, statusCode string, description string, statusMessage string) {
+	m.init()
+	resp := m.DescribeScalingActivitiesResp[asgName]
+	if resp == nil {
+		resp = &DescribeScalingActivitiesResponse{Resp: &autoscaling.DescribeScalingActivitiesOutput{}}
+		m.DescribeScalingActivitiesResp[asgName] = resp
+	}
+
+	resp.Resp.Activities = append(resp.Resp.Activities, &autoscaling.Activity{
+		AutoScalingGroupName: to.Strp(asgName),
+		StatusCode:           to.Strp(statusCode),
+		Description:          to.Strp(description),
+		StatusMessage:        to.Strp(statusMessage),
+		Cause:                to.Strp("cause"),
+	})
+}
+
+// DescribeScalingActivities returns
+func (m *ASGClient) DescribeScalingActivities(in *autoscaling.DescribeScalingActivitiesInput) (*autoscaling.DescribeScalingActivitiesOutput, error) {
+	m.init()
+	resp := m.DescribeScalingActivitiesResp[*in.AutoScalingGroupName]
+	if resp == nil {
+		return &autoscaling.DescribeScalingActivitiesOutput{}, nil
+	}
+	return resp.Resp, resp.Error
+}
+
 // EnableMetricsCollection returns
 func (m *ASGClient) EnableMetricsCollection(input *autoscaling.EnableMetricsCollectionInput) (*autoscaling.EnableMetricsCollectionOutput, error) {
 	return nil, nil
@@ -257,3 +351,29 @@ func (m *ASGClient) UpdateAutoScalingGroup(input *autoscaling.UpdateAutoScalingG
 	m.UpdateAutoScalingGroupLastInput = input
 	return nil, nil
 }
+
+// SuspendProcesses returns
+func (m *ASGClient) SuspendProcesses(input *autoscaling.ScalingProcessQuery) (*autoscaling.SuspendProcessesOutput, error) {
+	m.init()
+	m.SuspendedProcesses[*input.AutoScalingGroupName] = to.StrSlice(input.ScalingProcesses)
+	return nil, nil
+}
+
+// ResumeProcesses returns
+func (m *ASGClient) ResumeProcesses(input *autoscaling.ScalingProcessQuery) (*autoscaling.ResumeProcessesOutput, error) {
+	m.init()
+	if m.ResumeProcessesError != nil {
+		return nil, m.ResumeProcessesError
+	}
+
+	m.ResumedProcesses[*input.AutoScalingGroupName] = to.StrSlice(input.ScalingProcesses)
+	return nil, nil
+}
+
+// SetInstanceProtection returns
+func (m *ASGClient) SetInstanceProtection(input *autoscaling.SetInstanceProtectionInput) (*autoscaling.SetInstanceProtectionOutput, error) {
+	if !*input.ProtectedFromScaleIn {
+		m.UnprotectedIDs = append(m.UnprotectedIDs, to.StrSlice(input.InstanceIds)...)
+	}
+	return nil, nil
+}
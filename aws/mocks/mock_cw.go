@@ -1,6 +1,8 @@
 package mocks
 
 import (
+	"strings"
+
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/coinbase/odin/aws"
 )
@@ -8,10 +10,17 @@ import (
 // CWClient struct
 type CWClient struct {
 	aws.CWAPI
+	MetricAlarms  []*cloudwatch.MetricAlarm
+	DeletedAlarms []string
 }
 
 // DeleteAlarms returns
 func (m *CWClient) DeleteAlarms(input *cloudwatch.DeleteAlarmsInput) (*cloudwatch.DeleteAlarmsOutput, error) {
+	for _, name := range input.AlarmNames {
+		if name != nil {
+			m.DeletedAlarms = append(m.DeletedAlarms, *name)
+		}
+	}
 	return nil, nil
 }
 
@@ -19,3 +28,17 @@ func (m *CWClient) DeleteAlarms(input *cloudwatch.DeleteAlarmsInput) (*cloudwatc
 func (m *CWClient) PutMetricAlarm(input *cloudwatch.PutMetricAlarmInput) (*cloudwatch.PutMetricAlarmOutput, error) {
 	return nil, nil
 }
+
+// DescribeAlarmsPages returns
+func (m *CWClient) DescribeAlarmsPages(input *cloudwatch.DescribeAlarmsInput, fn func(*cloudwatch.DescribeAlarmsOutput, bool) bool) error {
+	alarms := []*cloudwatch.MetricAlarm{}
+	for _, alarm := range m.MetricAlarms {
+		if input.AlarmNamePrefix != nil && !strings.HasPrefix(*alarm.AlarmName, *input.AlarmNamePrefix) {
+			continue
+		}
+		alarms = append(alarms, alarm)
+	}
+
+	fn(&cloudwatch.DescribeAlarmsOutput{MetricAlarms: alarms}, true)
+	return nil
+}
@@ -1,8 +1,12 @@
 package mocks
 
 import (
+	"encoding/base64"
 	"fmt"
+	"path"
+	"strings"
 
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/coinbase/odin/aws"
 	"github.com/coinbase/step/utils/to"
@@ -33,6 +37,14 @@ type EC2Client struct {
 	DescribeSubnetsResp        *DescribeSubnetsResponse
 	DescribeImagesResp         *DescribeImagesResponse
 	PlacementGroups            []*ec2.PlacementGroup
+	ConsoleOutputs             map[string]string
+
+	InstanceTypes map[string]*ec2.InstanceTypeInfo
+	KeyPairs      map[string]*ec2.KeyPairInfo
+
+	LaunchTemplates        []*ec2.LaunchTemplate
+	CreatedLaunchTemplates []*ec2.CreateLaunchTemplateInput
+	DeletedLaunchTemplates []string
 }
 
 func (m *EC2Client) init() {
@@ -42,6 +54,12 @@ func (m *EC2Client) init() {
 	if m.PlacementGroups == nil {
 		m.PlacementGroups = []*ec2.PlacementGroup{}
 	}
+	if m.InstanceTypes == nil {
+		m.InstanceTypes = map[string]*ec2.InstanceTypeInfo{}
+	}
+	if m.KeyPairs == nil {
+		m.KeyPairs = map[string]*ec2.KeyPairInfo{}
+	}
 }
 
 // AddSecurityGroup returns
@@ -63,7 +81,9 @@ func (m *EC2Client) AddImage(nameTag string, id string) {
 		Resp: &ec2.DescribeImagesOutput{
 			Images: []*ec2.Image{
 				&ec2.Image{
-					ImageId: to.Strp(id),
+					ImageId:            to.Strp(id),
+					Architecture:       to.Strp("x86_64"),
+					VirtualizationType: to.Strp("hvm"),
 					Tags: []*ec2.Tag{
 						&ec2.Tag{Key: to.Strp("Name"), Value: to.Strp(nameTag)},
 						&ec2.Tag{Key: to.Strp("DeployWith"), Value: to.Strp("odin")},
@@ -91,9 +111,30 @@ func (m *EC2Client) AddSubnet(nameTag string, id string) {
 	}
 }
 
+// AddSecurityGroupWithID returns
+func (m *EC2Client) AddSecurityGroupWithID(id string, projectName string, configName string, serviceName string) {
+	m.init()
+	sg := MakeMockSecurityGroup(id, projectName, configName, serviceName)
+	sg.GroupId = to.Strp(id)
+	sg.Tags = sg.Tags[1:] // No Name tag
+	m.DescribeSecurityGroupsResp[id] = &DescribeSecurityGroupsResponse{
+		Resp: &ec2.DescribeSecurityGroupsOutput{
+			SecurityGroups: []*ec2.SecurityGroup{sg},
+		},
+	}
+}
+
 // DescribeSecurityGroups returns
 func (m *EC2Client) DescribeSecurityGroups(in *ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error) {
 	m.init()
+	if len(in.GroupIds) > 0 {
+		resp := m.DescribeSecurityGroupsResp[*in.GroupIds[0]]
+		if resp == nil {
+			return nil, awserr.New("InvalidGroup.NotFound", "InvalidGroup.NotFound", nil)
+		}
+		return resp.Resp, resp.Error
+	}
+
 	sgName := in.Filters[0].Values[0]
 	resp := m.DescribeSecurityGroupsResp[*sgName]
 	if resp == nil {
@@ -124,13 +165,73 @@ func (m *EC2Client) DescribeSubnets(in *ec2.DescribeSubnetsInput) (*ec2.Describe
 	return m.DescribeSubnetsResp.Resp, m.DescribeSubnetsResp.Error
 }
 
-// DescribeImages returns
+// AddImageVersion adds another image with the Name tag, DescribeImages returns all of them
+func (m *EC2Client) AddImageVersion(nameTag string, id string, creationDate string) {
+	if m.DescribeImagesResp == nil {
+		m.DescribeImagesResp = &DescribeImagesResponse{Resp: &ec2.DescribeImagesOutput{}}
+	}
+
+	m.DescribeImagesResp.Resp.Images = append(m.DescribeImagesResp.Resp.Images, &ec2.Image{
+		ImageId:            to.Strp(id),
+		CreationDate:       to.Strp(creationDate),
+		Architecture:       to.Strp("x86_64"),
+		VirtualizationType: to.Strp("hvm"),
+		Tags: []*ec2.Tag{
+			&ec2.Tag{Key: to.Strp("Name"), Value: to.Strp(nameTag)},
+			&ec2.Tag{Key: to.Strp("DeployWith"), Value: to.Strp("odin")},
+		},
+	})
+}
+
+// DescribeImages returns the added images, filtered by ImageIds and tag filters if given
 func (m *EC2Client) DescribeImages(in *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error) {
 	if m.DescribeImagesResp == nil {
 		return nil, fmt.Errorf("Add Image")
 	}
 
-	return m.DescribeImagesResp.Resp, m.DescribeImagesResp.Error
+	if m.DescribeImagesResp.Resp == nil {
+		return m.DescribeImagesResp.Resp, m.DescribeImagesResp.Error
+	}
+
+	ids := map[string]bool{}
+	for _, id := range in.ImageIds {
+		ids[to.Strs(id)] = true
+	}
+
+	images := []*ec2.Image{}
+	for _, im := range m.DescribeImagesResp.Resp.Images {
+		if im == nil || (len(ids) > 0 && !ids[to.Strs(im.ImageId)]) || !imageMatchesTagFilters(im, in.Filters) {
+			continue
+		}
+
+		images = append(images, im)
+	}
+
+	return &ec2.DescribeImagesOutput{Images: images}, m.DescribeImagesResp.Error
+}
+
+// imageMatchesTagFilters matches "tag:<key>" filters with the * and ? wildcards
+func imageMatchesTagFilters(im *ec2.Image, filters []*ec2.Filter) bool {
+	for _, f := range filters {
+		key := strings.TrimPrefix(to.Strs(f.Name), "tag:")
+		if key == to.Strs(f.Name) {
+			continue
+		}
+
+		value := aws.FetchEc2Tag(im.Tags, &key)
+		matched := false
+		for _, v := range f.Values {
+			if ok, _ := path.Match(to.Strs(v), to.Strs(value)); ok && value != nil {
+				matched = true
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	return true
 }
 
 func (m *EC2Client) DescribePlacementGroups(in *ec2.DescribePlacementGroupsInput) (*ec2.DescribePlacementGroupsOutput, error) {
@@ -151,3 +252,110 @@ func (m *EC2Client) CreatePlacementGroup(in *ec2.CreatePlacementGroupInput) (*ec
 
 	return nil, nil
 }
+
+// GetConsoleOutput returns
+func (m *EC2Client) GetConsoleOutput(in *ec2.GetConsoleOutputInput) (*ec2.GetConsoleOutputOutput, error) {
+	output, ok := m.ConsoleOutputs[*in.InstanceId]
+	if !ok {
+		return &ec2.GetConsoleOutputOutput{InstanceId: in.InstanceId}, nil
+	}
+
+	return &ec2.GetConsoleOutputOutput{
+		InstanceId: in.InstanceId,
+		Output:     to.Strp(base64.StdEncoding.EncodeToString([]byte(output))),
+	}, nil
+}
+
+// CreateLaunchTemplate returns
+func (m *EC2Client) CreateLaunchTemplate(input *ec2.CreateLaunchTemplateInput) (*ec2.CreateLaunchTemplateOutput, error) {
+	m.CreatedLaunchTemplates = append(m.CreatedLaunchTemplates, input)
+	m.AddLaunchTemplate(*input.LaunchTemplateName)
+	return &ec2.CreateLaunchTemplateOutput{
+		LaunchTemplate: &ec2.LaunchTemplate{LaunchTemplateName: input.LaunchTemplateName},
+	}, nil
+}
+
+// AddLaunchTemplate adds a launch template for DescribeLaunchTemplatesPages
+func (m *EC2Client) AddLaunchTemplate(name string) {
+	m.LaunchTemplates = append(m.LaunchTemplates, &ec2.LaunchTemplate{LaunchTemplateName: to.Strp(name)})
+}
+
+// DescribeLaunchTemplatesPages returns the added launch templates that are not deleted
+func (m *EC2Client) DescribeLaunchTemplatesPages(in *ec2.DescribeLaunchTemplatesInput, fn func(*ec2.DescribeLaunchTemplatesOutput, bool) bool) error {
+	deleted := map[string]bool{}
+	for _, name := range m.DeletedLaunchTemplates {
+		deleted[name] = true
+	}
+
+	lts := []*ec2.LaunchTemplate{}
+	for _, lt := range m.LaunchTemplates {
+		if !deleted[*lt.LaunchTemplateName] {
+			lts = append(lts, lt)
+		}
+	}
+
+	fn(&ec2.DescribeLaunchTemplatesOutput{LaunchTemplates: lts}, true)
+	return nil
+}
+
+// DeleteLaunchTemplate returns
+func (m *EC2Client) DeleteLaunchTemplate(input *ec2.DeleteLaunchTemplateInput) (*ec2.DeleteLaunchTemplateOutput, error) {
+	if input.LaunchTemplateName != nil {
+		m.DeletedLaunchTemplates = append(m.DeletedLaunchTemplates, *input.LaunchTemplateName)
+	}
+	return &ec2.DeleteLaunchTemplateOutput{}, nil
+}
+
+// AddInstanceType returns
+func (m *EC2Client) AddInstanceType(name string, architecture string, ebsOptimizedSupport string) {
+	m.init()
+	m.InstanceTypes[name] = &ec2.InstanceTypeInfo{
+		InstanceType:                  to.Strp(name),
+		EbsInfo:                       &ec2.EbsInfo{EbsOptimizedSupport: to.Strp(ebsOptimizedSupport)},
+		ProcessorInfo:                 &ec2.ProcessorInfo{SupportedArchitectures: []*string{to.Strp(architecture)}},
+		VCpuInfo:                      &ec2.VCpuInfo{DefaultVCpus: to.Int64p(2)},
+		MemoryInfo:                    &ec2.MemoryInfo{SizeInMiB: to.Int64p(2048)},
+		SupportedVirtualizationTypes:  []*string{to.Strp("hvm")},
+		BurstablePerformanceSupported: to.Boolp(strings.HasPrefix(name, "t")),
+	}
+}
+
+// DescribeInstanceTypes returns
+func (m *EC2Client) DescribeInstanceTypes(in *ec2.DescribeInstanceTypesInput) (*ec2.DescribeInstanceTypesOutput, error) {
+	m.init()
+	output := &ec2.DescribeInstanceTypesOutput{InstanceTypes: []*ec2.InstanceTypeInfo{}}
+	for _, name := range in.InstanceTypes {
+		info, ok := m.InstanceTypes[to.Strs(name)]
+		if !ok {
+			return nil, awserr.New("InvalidInstanceType", fmt.Sprintf("The following supplied instance types do not exist: [%v]", to.Strs(name)), nil)
+		}
+		output.InstanceTypes = append(output.InstanceTypes, info)
+	}
+	return output, nil
+}
+
+// AddKeyPair returns
+func (m *EC2Client) AddKeyPair(name string, deployWith string) {
+	m.init()
+	m.KeyPairs[name] = &ec2.KeyPairInfo{
+		KeyName:   to.Strp(name),
+		KeyPairId: to.Strp(fmt.Sprintf("key-%v", name)),
+		Tags: []*ec2.Tag{
+			&ec2.Tag{Key: to.Strp("DeployWith"), Value: to.Strp(deployWith)},
+		},
+	}
+}
+
+// DescribeKeyPairs returns
+func (m *EC2Client) DescribeKeyPairs(in *ec2.DescribeKeyPairsInput) (*ec2.DescribeKeyPairsOutput, error) {
+	m.init()
+	output := &ec2.DescribeKeyPairsOutput{KeyPairs: []*ec2.KeyPairInfo{}}
+	for _, name := range in.KeyNames {
+		kp, ok := m.KeyPairs[to.Strs(name)]
+		if !ok {
+			return nil, awserr.New("InvalidKeyPair.NotFound", fmt.Sprintf("The key pair '%v' does not exist", to.Strs(name)), nil)
+		}
+		output.KeyPairs = append(output.KeyPairs, kp)
+	}
+	return output, nil
+}
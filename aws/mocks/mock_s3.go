@@ -0,0 +1,34 @@
+package mocks
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/coinbase/step/aws/mocks"
+	"github.com/coinbase/step/utils/to"
+)
+
+// S3Client returns
+type S3Client struct {
+	*mocks.MockS3Client
+}
+
+// ListObjectsV2Pages returns the keys of the added objects under the prefix
+func (m *S3Client) ListObjectsV2Pages(in *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool) error {
+	keys := []string{}
+	for key := range m.GetObjectResp {
+		if strings.HasPrefix(key, to.Strs(in.Prefix)) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	objects := []*s3.Object{}
+	for _, key := range keys {
+		objects = append(objects, &s3.Object{Key: to.Strp(key)})
+	}
+
+	fn(&s3.ListObjectsV2Output{Contents: objects}, true)
+	return nil
+}
@@ -0,0 +1,76 @@
+package mocks
+
+import (
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/coinbase/step/aws/mocks"
+)
+
+// SFNClient returns
+type SFNClient struct {
+	*mocks.MockSFNClient
+	GetExecutionHistoryResps map[string]*sfn.GetExecutionHistoryOutput
+	DescribeExecutionResps   map[string]*sfn.DescribeExecutionOutput
+}
+
+// ListExecutionsPages returns
+func (m *SFNClient) ListExecutionsPages(in *sfn.ListExecutionsInput, fn func(*sfn.ListExecutionsOutput, bool) bool) error {
+	resp, err := m.ListExecutions(in)
+	if err != nil {
+		return err
+	}
+
+	executions := []*sfn.ExecutionListItem{}
+	for _, e := range resp.Executions {
+		if in.StatusFilter != nil && e.Status != nil && *e.Status != *in.StatusFilter {
+			continue
+		}
+		executions = append(executions, e)
+	}
+
+	fn(&sfn.ListExecutionsOutput{Executions: executions}, true)
+	return nil
+}
+
+// GetExecutionHistory returns the history for the execution ARN if added, otherwise the default
+func (m *SFNClient) GetExecutionHistory(in *sfn.GetExecutionHistoryInput) (*sfn.GetExecutionHistoryOutput, error) {
+	if in.ExecutionArn != nil {
+		if resp, ok := m.GetExecutionHistoryResps[*in.ExecutionArn]; ok {
+			return resp, nil
+		}
+	}
+
+	return m.MockSFNClient.GetExecutionHistory(in)
+}
+
+// AddExecutionHistory returns
+func (m *SFNClient) AddExecutionHistory(arn string, events ...*sfn.HistoryEvent) {
+	if m.GetExecutionHistoryResps == nil {
+		m.GetExecutionHistoryResps = map[string]*sfn.GetExecutionHistoryOutput{}
+	}
+
+	m.GetExecutionHistoryResps[arn] = &sfn.GetExecutionHistoryOutput{Events: events}
+}
+
+// DescribeExecution returns the execution for the ARN if added, otherwise the default
+func (m *SFNClient) DescribeExecution(in *sfn.DescribeExecutionInput) (*sfn.DescribeExecutionOutput, error) {
+	if in.ExecutionArn != nil {
+		if resp, ok := m.DescribeExecutionResps[*in.ExecutionArn]; ok {
+			return resp, nil
+		}
+	}
+
+	return m.MockSFNClient.DescribeExecution(in)
+}
+
+// AddDescribeExecution returns
+func (m *SFNClient) AddDescribeExecution(arn string, status string, input string) {
+	if m.DescribeExecutionResps == nil {
+		m.DescribeExecutionResps = map[string]*sfn.DescribeExecutionOutput{}
+	}
+
+	m.DescribeExecutionResps[arn] = &sfn.DescribeExecutionOutput{
+		ExecutionArn: &arn,
+		Status:       &status,
+		Input:        &input,
+	}
+}
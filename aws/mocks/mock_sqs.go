@@ -0,0 +1,25 @@
+package mocks
+
+import (
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/utils/to"
+)
+
+// SQSClient returns
+type SQSClient struct {
+	aws.SQSAPI
+	MissingQueues []string
+}
+
+// GetQueueUrl returns
+func (m *SQSClient) GetQueueUrl(in *sqs.GetQueueUrlInput) (*sqs.GetQueueUrlOutput, error) {
+	for _, name := range m.MissingQueues {
+		if name == *in.QueueName {
+			return nil, awserr.New(sqs.ErrCodeQueueDoesNotExist, "AWS.SimpleQueueService.NonExistentQueue", nil)
+		}
+	}
+
+	return &sqs.GetQueueUrlOutput{QueueUrl: to.Strp("https://sqs/" + *in.QueueName)}, nil
+}
@@ -2,6 +2,7 @@ package sg
 
 import (
 	"fmt"
+	"regexp"
 
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/coinbase/odin/aws"
@@ -32,8 +33,11 @@ func (s *SecurityGroup) ServiceName() *string {
 	return s.ServiceNameTag
 }
 
-// Name returns tag
+// Name returns tag, or the ID if the group has no Name tag
 func (s *SecurityGroup) Name() *string {
+	if s.NameTag == nil {
+		return s.GroupID
+	}
 	return s.NameTag
 }
 
@@ -42,8 +46,85 @@ func (s *SecurityGroup) AllowedService() *string {
 	return to.Strp(fmt.Sprintf("%s::%s::%s", *s.ProjectName(), *s.ConfigName(), *s.ServiceName()))
 }
 
-// Find returns the security groups with tags
-func Find(ec2Client aws.EC2API, nameTags []*string) ([]*SecurityGroup, error) {
+// Find returns the security groups for either sg- IDs or Name tags, e.g. sg-0123456789abcdef0 or web-sg
+func Find(ec2Client aws.EC2API, nameTagsOrIDs []*string) ([]*SecurityGroup, error) {
+	ids, nameTags := splitIDsTags(nameTagsOrIDs)
+
+	sgs := []*SecurityGroup{}
+
+	if len(ids) > 0 {
+		found, err := findByID(ec2Client, ids)
+		if err != nil {
+			return nil, err
+		}
+		sgs = append(sgs, found...)
+	}
+
+	if len(nameTags) > 0 {
+		found, err := findByNameTag(ec2Client, nameTags)
+		if err != nil {
+			return nil, err
+		}
+		sgs = append(sgs, found...)
+	}
+
+	if len(sgs) != len(nameTagsOrIDs) {
+		// Last assurance that no additional security groups were found
+		return nil, fmt.Errorf("SecurityGroup: found %v required %v", len(sgs), len(nameTagsOrIDs))
+	}
+
+	return sgs, nil
+}
+
+var idRegex = regexp.MustCompile(`^sg-([0-9a-f]{8}|[0-9a-f]{17})$`)
+
+// isID sees if a string is a security group ID
+func isID(name string) bool {
+	return idRegex.MatchString(name)
+}
+
+// splitIDsTags returns list of ids, and list of tags
+func splitIDsTags(nameTagsOrIDs []*string) ([]*string, []*string) {
+	ids := []*string{}
+	tags := []*string{}
+	for _, n := range nameTagsOrIDs {
+		if isID(*n) {
+			ids = append(ids, n)
+		} else {
+			tags = append(tags, n)
+		}
+	}
+	return ids, tags
+}
+
+func findByID(ec2Client aws.EC2API, ids []*string) ([]*SecurityGroup, error) {
+	output, err := ec2Client.DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{
+		GroupIds: ids,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sgs := newSGs(output.SecurityGroups)
+
+	for _, id := range ids {
+		found := false
+		for _, sg := range sgs {
+			if to.Strs(sg.GroupID) == *id {
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("SecurityGroup '%v': not found", *id)
+		}
+	}
+
+	return sgs, nil
+}
+
+func findByNameTag(ec2Client aws.EC2API, nameTags []*string) ([]*SecurityGroup, error) {
 	output, err := ec2Client.DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{
 		Filters: []*ec2.Filter{
 			&ec2.Filter{
@@ -80,11 +161,6 @@ func Find(ec2Client aws.EC2API, nameTags []*string) ([]*SecurityGroup, error) {
 		}
 	}
 
-	if len(sgs) != len(nameTags) {
-		// Last assurance that no additional security groups were found
-		return nil, fmt.Errorf("SecurityGroup: found %v required %v", len(sgs), len(nameTags))
-	}
-
 	return sgs, nil
 }
 
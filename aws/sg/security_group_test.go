@@ -20,3 +20,23 @@ func Test_Find(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(sgs))
 }
+
+func Test_Find_ByID(t *testing.T) {
+	ec2c := &mocks.EC2Client{}
+	_, err := Find(ec2c, []*string{to.Strp("sg-0123456789abcdef0")})
+	assert.Error(t, err)
+
+	ec2c.AddSecurityGroupWithID("sg-0123456789abcdef0", "project_name", "config_name", "service_name")
+	sgs, err := Find(ec2c, []*string{to.Strp("sg-0123456789abcdef0")})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(sgs))
+	assert.Equal(t, "sg-0123456789abcdef0", *sgs[0].Name())
+	assert.Equal(t, "project_name", *sgs[0].ProjectName())
+}
+
+func Test_isID(t *testing.T) {
+	assert.True(t, isID("sg-01234567"))
+	assert.True(t, isID("sg-0123456789abcdef0"))
+	assert.False(t, isID("sg-web"))
+	assert.False(t, isID("web-sg"))
+}
@@ -0,0 +1,28 @@
+package sqs
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/coinbase/odin/aws"
+)
+
+// QueueExists errors if SQS queue doesn't exists
+func QueueExists(sqsc aws.SQSAPI, queueARN *string) error {
+	if queueARN == nil {
+		return fmt.Errorf("SQS queue ARN nil")
+	}
+
+	a, err := arn.Parse(*queueARN)
+	if err != nil {
+		return err
+	}
+
+	_, err = sqsc.GetQueueUrl(&sqs.GetQueueUrlInput{
+		QueueName:              &a.Resource,
+		QueueOwnerAWSAccountId: &a.AccountID,
+	})
+
+	return err
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math"
+	"os"
+	"os/user"
 	"sort"
 	"strings"
 	"time"
@@ -54,6 +56,23 @@ func prepareRelease(release *models.Release, region *string, accountID *string)
 
 	release.ReleaseID = to.TimeUUID("release-")
 	release.CreatedAt = to.Timep(time.Now())
+
+	// Recorded so `odin history` can show who started the release
+	if release.Metadata == nil {
+		release.Metadata = map[string]string{}
+	}
+
+	if _, ok := release.Metadata["deployed_by"]; !ok {
+		release.Metadata["deployed_by"] = deployedBy()
+	}
+}
+
+func deployedBy() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+
+	return os.Getenv("USER")
 }
 
 func parseRelease(releaseFile string) (*models.Release, error) {
@@ -152,10 +171,22 @@ func waiterStr(status *string, sd *execution.StateDetails) (string, error) {
 					sh = append(sh, st)
 				}
 			}
+			if wave, waves := release.ActiveWave(); waves > 1 {
+				newLine = fmt.Sprintf("%v wave %v/%v", newLine, wave, waves)
+			}
+
 			if len(sh) > 0 {
 				sort.Strings(sh)
 				newLine = fmt.Sprintf("%v %v", newLine, strings.Join(sh, "  "))
 			}
+
+			if len(release.RetiringASGs) > 0 {
+				newLine = fmt.Sprintf("%v retiring %v", newLine, strings.Join(to.StrSlice(release.RetiringASGs), ","))
+			}
+
+			if cs := costStr(release.CostEstimate); cs != "" {
+				newLine = fmt.Sprintf("%v %v", newLine, cs)
+			}
 		}
 	}
 
@@ -200,8 +231,30 @@ func serviceStr(name string, service *models.Service) string {
 				dots = append(dots, fmt.Sprintf("%v.%v", GRAY, NC))
 			}
 		}
+		if service.HealthReport.WaitingOn != nil {
+			return fmt.Sprintf("%s: %v (%v)", name, strings.Join(dots, ""), *service.HealthReport.WaitingOn)
+		}
 		return fmt.Sprintf("%s: %v", name, strings.Join(dots, ""))
 	}
 
 	return ""
 }
+
+// costStr returns the estimated cost and its change from the deployed release
+func costStr(estimate *models.CostEstimate) string {
+	if estimate == nil || estimate.Hourly == nil || estimate.Monthly == nil {
+		return ""
+	}
+
+	cs := fmt.Sprintf("(~$%.2f/hour $%.2f/month", *estimate.Hourly, *estimate.Monthly)
+	if estimate.PreviousMonthly != nil {
+		increase := estimate.MonthlyIncrease()
+		sign := "+"
+		if increase < 0 {
+			sign = "-"
+		}
+		cs = fmt.Sprintf("%v, %v$%.2f/month", cs, sign, math.Abs(increase))
+	}
+
+	return fmt.Sprintf("%v)", cs)
+}
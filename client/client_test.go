@@ -61,3 +61,30 @@ func Test_waiterStr(t *testing.T) {
 
 	waiterStrTest(t, r) // Checks errors
 }
+
+func Test_costStr(t *testing.T) {
+	assert.Equal(t, "", costStr(nil))
+
+	estimate := &models.CostEstimate{Hourly: to.Float64p(0.3944), Monthly: to.Float64p(287.91)}
+	assert.Equal(t, "(~$0.39/hour $287.91/month)", costStr(estimate))
+
+	estimate.PreviousMonthly = to.Float64p(28.79)
+	assert.Equal(t, "(~$0.39/hour $287.91/month, +$259.12/month)", costStr(estimate))
+
+	estimate.PreviousMonthly = to.Float64p(300)
+	assert.Equal(t, "(~$0.39/hour $287.91/month, -$12.09/month)", costStr(estimate))
+}
+
+func Test_waiterStr_Waves(t *testing.T) {
+	r := minimalRelease(t)
+	r.Services["worker"] = &models.Service{DependsOn: []*string{to.Strp("web")}}
+
+	assert.Contains(t, waiterStrTest(t, r), "wave 1/2")
+}
+
+func Test_waiterStr_Retiring(t *testing.T) {
+	r := minimalRelease(t)
+	r.RetiringASGs = []*string{to.Strp("worker-asg")}
+
+	assert.Contains(t, waiterStrTest(t, r), "retiring worker-asg")
+}
@@ -1,7 +1,10 @@
 package client
 
 import (
+	"flag"
 	"fmt"
+	"io/ioutil"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/service/sfn/sfniface"
 	"github.com/coinbase/odin/aws"
@@ -11,14 +14,58 @@ import (
 	"github.com/coinbase/step/utils/to"
 )
 
+// DeployOptions for `odin deploy`
+type DeployOptions struct {
+	File string
+	Only []string // Services to deploy, the others keep their ASGs
+}
+
+// DeployOptionsFromArgs parses `odin deploy <release_file> [--only web,worker]`
+func DeployOptionsFromArgs(args []string) (*DeployOptions, error) {
+	if len(args) < 1 {
+		return nil, fmt.Errorf("deploy requires <release_file>")
+	}
+
+	opts := DeployOptions{File: args[0]}
+	var only string
+
+	fs := flag.NewFlagSet("deploy", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	fs.StringVar(&only, "only", "", "comma separated services to deploy, the others keep their ASGs")
+
+	if err := fs.Parse(args[1:]); err != nil {
+		return nil, err
+	}
+
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments %v", fs.Args())
+	}
+
+	for _, name := range strings.Split(only, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			opts.Only = append(opts.Only, name)
+		}
+	}
+
+	return &opts, nil
+}
+
 // Deploy attempts to deploy release
-func Deploy(step_fn *string, releaseFile *string) error {
+func Deploy(step_fn *string, opts *DeployOptions) error {
 	region, accountID := to.RegionAccount()
-	release, err := releaseFromFile(releaseFile, region, accountID)
+	release, err := releaseFromFile(&opts.File, region, accountID)
 	if err != nil {
 		return err
 	}
 
+	// --only overrides the services_to_deploy in the file
+	if len(opts.Only) > 0 {
+		release.ServicesToDeploy = []*string{}
+		for _, name := range opts.Only {
+			release.ServicesToDeploy = append(release.ServicesToDeploy, to.Strp(name))
+		}
+	}
+
 	deployerARN := to.StepArn(region, accountID, step_fn)
 
 	return deploy(&aws.ClientsStr{}, release, deployerARN)
@@ -17,3 +17,20 @@ func Test_Deploy(t *testing.T) {
 	err := deploy(awsc, r, to.Strp("deployerARN"))
 	assert.NoError(t, err)
 }
+
+func Test_DeployOptionsFromArgs(t *testing.T) {
+	opts, err := DeployOptionsFromArgs([]string{"release.json"})
+	assert.NoError(t, err)
+	assert.Equal(t, "release.json", opts.File)
+	assert.Equal(t, 0, len(opts.Only))
+
+	opts, err = DeployOptionsFromArgs([]string{"release.json", "--only", "web, worker"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"web", "worker"}, opts.Only)
+
+	_, err = DeployOptionsFromArgs([]string{})
+	assert.Error(t, err)
+
+	_, err = DeployOptionsFromArgs([]string{"release.json", "extra"})
+	assert.Error(t, err)
+}
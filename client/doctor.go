@@ -0,0 +1,388 @@
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/aws/alarms"
+	"github.com/coinbase/odin/aws/asg"
+	"github.com/coinbase/odin/aws/lc"
+	"github.com/coinbase/odin/aws/lt"
+	"github.com/coinbase/odin/deployer/models"
+	"github.com/coinbase/step/aws/s3"
+	"github.com/coinbase/step/bifrost"
+	"github.com/coinbase/step/utils/is"
+	"github.com/coinbase/step/utils/to"
+)
+
+// DoctorOptions for `odin doctor`
+type DoctorOptions struct {
+	Project string
+	Config  string
+	Fix     bool
+
+	// Live is the release ID to treat as live when the Step Function history has no successful execution
+	Live string
+}
+
+// DoctorFinding is a resource for the project-config that is not part of the live or running release
+type DoctorFinding struct {
+	Type   string // AutoScalingGroup | LaunchConfiguration | LaunchTemplate | Alarm
+	Name   string
+	Reason string
+
+	asg *asg.ASG
+}
+
+// doctorExam is what the doctor found
+type doctorExam struct {
+	LiveReleaseID    *string // nil if the live release cannot be proven
+	RunningReleaseID *string
+	Findings         []*DoctorFinding
+}
+
+// DoctorOptionsFromArgs parses `odin doctor <project> <config> [--fix] [--live <release_id>]`
+func DoctorOptionsFromArgs(args []string) (*DoctorOptions, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("doctor requires <project> <config>")
+	}
+
+	opts := DoctorOptions{Project: args[0], Config: args[1]}
+
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	fs.BoolVar(&opts.Fix, "fix", false, "tear down the orphaned resources after confirmation")
+	fs.StringVar(&opts.Live, "live", "", "release ID of the live release")
+
+	if err := fs.Parse(args[2:]); err != nil {
+		return nil, err
+	}
+
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments %v", fs.Args())
+	}
+
+	return &opts, nil
+}
+
+// Doctor finds resources left behind for a project-config and optionally tears them down
+func Doctor(step_fn *string, opts *DoctorOptions) error {
+	region, accountID := to.RegionAccount()
+
+	deployerARN := to.StepArn(region, accountID, step_fn)
+
+	return doctor(&aws.ClientsStr{}, deployerARN, region, accountID, opts, os.Stdin)
+}
+
+func doctor(awsc aws.Clients, arn *string, region *string, accountID *string, opts *DoctorOptions, in io.Reader) error {
+	exam, err := examine(awsc, arn, region, accountID, opts)
+	if err != nil {
+		return err
+	}
+
+	if exam.LiveReleaseID == nil {
+		fmt.Println("Live release:    unknown, no successful execution in the Step Function history")
+	} else {
+		fmt.Printf("Live release:    %v\n", *exam.LiveReleaseID)
+	}
+	fmt.Printf("Running release: %v\n", to.Strs(exam.RunningReleaseID))
+
+	if len(exam.Findings) == 0 {
+		fmt.Println("No orphaned resources found")
+		return nil
+	}
+
+	for _, f := range exam.Findings {
+		fmt.Printf("%v %v\n  %v\n", f.Type, f.Name, f.Reason)
+	}
+
+	if !opts.Fix {
+		fmt.Println("Run with --fix to tear these down")
+		return nil
+	}
+
+	// Without a live release every ASG not running would be torn down, including the live ones
+	if exam.LiveReleaseID == nil {
+		return fmt.Errorf("Cannot prove which release is live, run with --live <release_id> to fix")
+	}
+
+	fmt.Printf("Tear down %v resources? Type 'yes' to confirm: ", len(exam.Findings))
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	if strings.TrimSpace(answer) != "yes" {
+		fmt.Println("Aborted")
+		return nil
+	}
+
+	return fix(awsc, exam.Findings)
+}
+
+func examine(awsc aws.Clients, arn *string, region *string, accountID *string, opts *DoctorOptions) (*doctorExam, error) {
+	asgc := awsc.ASGClient(nil, nil, nil)
+	cwc := awsc.CWClient(nil, nil, nil)
+	ec2c := awsc.EC2Client(nil, nil, nil)
+	sfnc := awsc.SFNClient(nil, nil, nil)
+	s3c := awsc.S3Client(nil, nil, nil)
+
+	exam := &doctorExam{Findings: []*DoctorFinding{}}
+	prefix := (&bifrost.Release{ProjectName: &opts.Project, ConfigName: &opts.Config}).ExecutionPrefix()
+
+	running, err := latestRelease(sfnc, s3c, arn, "RUNNING", prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	live, err := liveRelease(sfnc, s3c, arn, region, accountID, prefix, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	asgs, err := asg.ForProjectConfig(asgc, &opts.Project, &opts.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resource names from the live and running release start with these prefixes
+	keep := map[string]bool{}
+	keepASGs := map[string]bool{}
+	protectedPrefixes := []string{}
+	for _, r := range []*models.Release{live, running} {
+		if r == nil || r.ReleaseID == nil {
+			continue
+		}
+
+		keep[*r.ReleaseID] = true
+
+		// A partial release keeps ASGs that are still tagged with an older release ID
+		for _, service := range r.Services {
+			if service != nil && service.CarriedASG != nil {
+				keepASGs[*service.CarriedASG] = true
+			}
+		}
+
+		// The previous ASGs of a Drain TeardownStrategy are deleted by the deployer once their hooks finish
+		for _, name := range r.DrainingASGs {
+			if name != nil {
+				keepASGs[*name] = true
+			}
+		}
+
+		if r.CreatedAt != nil {
+			protectedPrefixes = append(protectedPrefixes, models.ReleaseServiceIDPrefix(opts.Project, opts.Config, *r.CreatedAt))
+		}
+	}
+
+	if live != nil {
+		exam.LiveReleaseID = live.ReleaseID
+	}
+
+	if running != nil {
+		exam.RunningReleaseID = running.ReleaseID
+	}
+
+	// ASGs
+	asgNames := []string{}
+	lcNames := map[string]bool{}
+	ltNames := map[string]bool{}
+	for _, group := range asgs {
+		asgNames = append(asgNames, to.Strs(group.ServiceID()))
+		lcNames[to.Strs(group.LaunchConfigurationName)] = true
+		ltNames[to.Strs(group.LaunchTemplateName)] = true
+
+		releaseID := group.ReleaseID()
+		switch {
+		case keepASGs[to.Strs(group.AutoScalingGroupName)]:
+			continue
+		case releaseID == nil:
+			exam.Findings = append(exam.Findings, &DoctorFinding{
+				Type:   "AutoScalingGroup",
+				Name:   to.Strs(group.ServiceID()),
+				Reason: "is tagged for the project config but has no ReleaseID tag",
+				asg:    group,
+			})
+		case !keep[*releaseID]:
+			exam.Findings = append(exam.Findings, &DoctorFinding{
+				Type:   "AutoScalingGroup",
+				Name:   to.Strs(group.ServiceID()),
+				Reason: fmt.Sprintf("belongs to release %v which is not live or running, likely left by a FailureDirty execution", *releaseID),
+				asg:    group,
+			})
+		}
+	}
+
+	// Launch configurations not used by any ASG, those used by an orphaned ASG are deleted with it
+	lcs, err := lc.FindByPrefix(asgc, models.ProjectConfigServiceIDPrefix(opts.Project, opts.Config))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, l := range lcs {
+		name := *l.LaunchConfigurationName
+		if lcNames[name] || !models.HasProjectConfigServiceIDPrefix(name, opts.Project, opts.Config) || models.HasAnyPrefix(name, protectedPrefixes) {
+			continue
+		}
+
+		exam.Findings = append(exam.Findings, &DoctorFinding{
+			Type:   "LaunchConfiguration",
+			Name:   name,
+			Reason: "is not used by any ASG",
+		})
+	}
+
+	// Launch templates not used by any ASG, those used by an orphaned ASG are deleted with it
+	lts, err := lt.FindByPrefix(ec2c, models.ProjectConfigServiceIDPrefix(opts.Project, opts.Config))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, l := range lts {
+		name := *l.LaunchTemplateName
+		if ltNames[name] || !models.HasProjectConfigServiceIDPrefix(name, opts.Project, opts.Config) || models.HasAnyPrefix(name, protectedPrefixes) {
+			continue
+		}
+
+		exam.Findings = append(exam.Findings, &DoctorFinding{
+			Type:   "LaunchTemplate",
+			Name:   name,
+			Reason: "is not used by any ASG",
+		})
+	}
+
+	// Alarms are named "<ServiceID>-<type>", those for an existing ASG are deleted with its policies
+	asgPrefixes := []string{}
+	for _, name := range asgNames {
+		asgPrefixes = append(asgPrefixes, fmt.Sprintf("%v-", name))
+	}
+
+	metricAlarms, err := alarms.FindByPrefix(cwc, models.ProjectConfigServiceIDPrefix(opts.Project, opts.Config))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range metricAlarms {
+		name := to.Strs(a.AlarmName)
+		if !models.HasProjectConfigServiceIDPrefix(name, opts.Project, opts.Config) || models.HasAnyPrefix(name, asgPrefixes) || models.HasAnyPrefix(name, protectedPrefixes) {
+			continue
+		}
+
+		exam.Findings = append(exam.Findings, &DoctorFinding{
+			Type:   "Alarm",
+			Name:   name,
+			Reason: "is not for any ASG",
+		})
+	}
+
+	return exam, nil
+}
+
+func fix(awsc aws.Clients, findings []*DoctorFinding) error {
+	asgc := awsc.ASGClient(nil, nil, nil)
+	cwc := awsc.CWClient(nil, nil, nil)
+	ec2c := awsc.EC2Client(nil, nil, nil)
+
+	alarmNames := []*string{}
+	for _, f := range findings {
+		switch f.Type {
+		case "AutoScalingGroup":
+			if err := f.asg.Teardown(asgc, cwc, ec2c); err != nil {
+				return err
+			}
+		case "LaunchConfiguration":
+			if err := lc.Teardown(asgc, to.Strp(f.Name)); err != nil {
+				return err
+			}
+		case "LaunchTemplate":
+			if err := lt.Teardown(ec2c, to.Strp(f.Name)); err != nil {
+				return err
+			}
+		case "Alarm":
+			alarmNames = append(alarmNames, to.Strp(f.Name))
+		}
+		fmt.Printf("Deleted %v %v\n", f.Type, f.Name)
+	}
+
+	return alarms.Teardown(cwc, alarmNames)
+}
+
+// liveRelease returns the --live release from S3, or the release of the newest successful execution,
+// nil if there is neither
+func liveRelease(sfnc aws.SFNAPI, s3c aws.S3API, arn *string, region *string, accountID *string, prefix string, opts *DoctorOptions) (*models.Release, error) {
+	if opts.Live == "" {
+		return latestRelease(sfnc, s3c, arn, "SUCCEEDED", prefix)
+	}
+
+	live := &models.Release{
+		Release: bifrost.Release{
+			ProjectName: &opts.Project,
+			ConfigName:  &opts.Config,
+			ReleaseID:   &opts.Live,
+		},
+	}
+	live.Release.SetDefaults(region, accountID, "coinbase-odin-")
+
+	if err := s3.GetStruct(s3c, live.Bucket, live.ReleasePath(), live); err != nil {
+		return nil, fmt.Errorf("Cannot find live release s3://%v/%v: %v", *live.Bucket, *live.ReleasePath(), err.Error())
+	}
+
+	return live, nil
+}
+
+// latestRelease returns the release of the newest execution with the status and prefix, nil if there is none
+func latestRelease(sfnc aws.SFNAPI, s3c aws.S3API, arn *string, status string, prefix string) (*models.Release, error) {
+	var found *sfn.ExecutionListItem
+
+	pagefn := func(page *sfn.ListExecutionsOutput, lastPage bool) bool {
+		for _, e := range page.Executions {
+			if e.Name != nil && strings.HasPrefix(*e.Name, prefix) {
+				found = e
+				return false
+			}
+		}
+		return !lastPage
+	}
+
+	err := sfnc.ListExecutionsPages(&sfn.ListExecutionsInput{
+		MaxResults:      to.Int64p(100),
+		StateMachineArn: arn,
+		StatusFilter:    &status,
+	}, pagefn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if found == nil {
+		return nil, nil
+	}
+
+	out, err := sfnc.DescribeExecution(&sfn.DescribeExecutionInput{ExecutionArn: found.ExecutionArn})
+	if err != nil {
+		return nil, err
+	}
+
+	if out.Input == nil {
+		return nil, fmt.Errorf("execution %v has no input", *found.ExecutionArn)
+	}
+
+	var release models.Release
+	if err := json.Unmarshal([]byte(*out.Input), &release); err != nil {
+		return nil, err
+	}
+
+	// The release uploaded to S3 also has the values the deployer set
+	if !is.EmptyStr(release.Bucket) && !is.EmptyStr(release.AwsAccountID) && !is.EmptyStr(release.ReleaseID) {
+		var s3Release models.Release
+		if err := s3.GetStruct(s3c, release.Bucket, release.ReleasePath(), &s3Release); err == nil {
+			release = s3Release
+		}
+	}
+
+	return &release, nil
+}
@@ -0,0 +1,249 @@
+package client
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/odin/deployer/models"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockDoctor(t *testing.T) (*mocks.MockClients, string, string, string) {
+	awsc := mocks.MockAWS()
+
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Hour)
+	t2 := t1.Add(time.Hour)
+
+	// Live release
+	live := minimalRelease(t)
+	live.ReleaseID = to.Strp("release-1")
+	live.CreatedAt = &t1
+	input, _ := to.PrettyJSON(live)
+
+	awsc.SFN.ListExecutionsResp = &sfn.ListExecutionsOutput{
+		Executions: []*sfn.ExecutionListItem{
+			&sfn.ExecutionListItem{
+				Name:         to.Strp("deploy-project-config-1"),
+				ExecutionArn: to.Strp("arn1"),
+				Status:       to.Strp("SUCCEEDED"),
+			},
+		},
+	}
+	awsc.SFN.AddDescribeExecution("arn1", "SUCCEEDED", input)
+
+	liveName := models.ReleaseServiceIDPrefix("project", "config", t1) + "web"
+	orphanName := models.ReleaseServiceIDPrefix("project", "config", t0) + "web"
+	leakedName := models.ReleaseServiceIDPrefix("project", "config", t2) + "web"
+
+	liveASG := mocks.MakeMockASG(liveName, "project", "config", "web", "release-1")
+	liveASG.LaunchConfigurationName = to.Strp(liveName)
+	awsc.ASG.AddASG(liveASG)
+
+	// Left by a FailureDirty execution
+	orphanASG := mocks.MakeMockASG(orphanName, "project", "config", "web", "release-0")
+	orphanASG.LaunchConfigurationName = to.Strp(orphanName)
+	awsc.ASG.AddASG(orphanASG)
+
+	awsc.ASG.AddLaunchConfiguration(liveName)
+	awsc.ASG.AddLaunchConfiguration(orphanName)
+	awsc.ASG.AddLaunchConfiguration(leakedName)                                        // ASG creation failed
+	awsc.ASG.AddLaunchConfiguration("project-config-staging-2020-01-01T00-00-00Z-web") // Not this config
+
+	awsc.CW.MetricAlarms = []*cloudwatch.MetricAlarm{
+		&cloudwatch.MetricAlarm{AlarmName: to.Strp(liveName + "-cpu_scale_up")},
+		&cloudwatch.MetricAlarm{AlarmName: to.Strp(leakedName + "-cpu_scale_up")},
+	}
+
+	return awsc, liveName, orphanName, leakedName
+}
+
+func Test_Doctor_Examine(t *testing.T) {
+	awsc, _, orphanName, leakedName := mockDoctor(t)
+
+	exam, err := examine(awsc, to.Strp("arn"), to.Strp("region"), to.Strp("000000"), &DoctorOptions{Project: "project", Config: "config"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "release-1", *exam.LiveReleaseID)
+	assert.Nil(t, exam.RunningReleaseID)
+	assert.Equal(t, 3, len(exam.Findings))
+
+	assert.Equal(t, "AutoScalingGroup", exam.Findings[0].Type)
+	assert.Equal(t, orphanName, exam.Findings[0].Name)
+	assert.Contains(t, exam.Findings[0].Reason, "release-0")
+
+	assert.Equal(t, "LaunchConfiguration", exam.Findings[1].Type)
+	assert.Equal(t, leakedName, exam.Findings[1].Name)
+
+	assert.Equal(t, "Alarm", exam.Findings[2].Type)
+	assert.Equal(t, leakedName+"-cpu_scale_up", exam.Findings[2].Name)
+}
+
+func Test_Doctor_Running_Release_Is_Kept(t *testing.T) {
+	awsc, _, _, _ := mockDoctor(t)
+
+	running := minimalRelease(t)
+	running.ReleaseID = to.Strp("release-0")
+	running.CreatedAt = to.Timep(time.Date(2020, 1, 1, 2, 0, 0, 0, time.UTC))
+	input, _ := to.PrettyJSON(running)
+
+	awsc.SFN.ListExecutionsResp.Executions = append(awsc.SFN.ListExecutionsResp.Executions, &sfn.ExecutionListItem{
+		Name:         to.Strp("deploy-project-config-2"),
+		ExecutionArn: to.Strp("arn2"),
+		Status:       to.Strp("RUNNING"),
+	})
+	awsc.SFN.AddDescribeExecution("arn2", "RUNNING", input)
+
+	exam, err := examine(awsc, to.Strp("arn"), to.Strp("region"), to.Strp("000000"), &DoctorOptions{Project: "project", Config: "config"})
+	assert.NoError(t, err)
+	assert.Equal(t, "release-0", *exam.RunningReleaseID)
+	assert.Equal(t, 0, len(exam.Findings))
+}
+
+func Test_Doctor_Fix(t *testing.T) {
+	awsc, liveName, orphanName, leakedName := mockDoctor(t)
+	opts := &DoctorOptions{Project: "project", Config: "config", Fix: true}
+
+	// Not confirmed
+	assert.NoError(t, doctor(awsc, to.Strp("arn"), to.Strp("region"), to.Strp("000000"), opts, strings.NewReader("no\n")))
+	assert.Equal(t, 0, len(awsc.ASG.DeletedAutoScalingGroups))
+
+	assert.NoError(t, doctor(awsc, to.Strp("arn"), to.Strp("region"), to.Strp("000000"), opts, strings.NewReader("yes\n")))
+	assert.Equal(t, []string{orphanName}, awsc.ASG.DeletedAutoScalingGroups)
+	assert.Equal(t, []string{orphanName, leakedName}, awsc.ASG.DeletedLaunchConfigurations)
+	assert.Contains(t, awsc.CW.DeletedAlarms, leakedName+"-cpu_scale_up")
+	assert.NotContains(t, awsc.CW.DeletedAlarms, liveName+"-cpu_scale_up")
+}
+
+func Test_Doctor_No_Successful_Execution(t *testing.T) {
+	awsc, liveName, orphanName, _ := mockDoctor(t)
+	awsc.SFN.ListExecutionsResp.Executions = []*sfn.ExecutionListItem{}
+
+	// The newest ASG is the orphan but nothing proves which release is live
+	awsc.ASG.DescribeAutoScalingGroupsPageResp[1].Resp.AutoScalingGroups[0].CreatedTime = to.Timep(time.Now())
+
+	exam, err := examine(awsc, to.Strp("arn"), to.Strp("region"), to.Strp("000000"), &DoctorOptions{Project: "project", Config: "config"})
+	assert.NoError(t, err)
+	assert.Nil(t, exam.LiveReleaseID)
+
+	opts := &DoctorOptions{Project: "project", Config: "config", Fix: true}
+	err = doctor(awsc, to.Strp("arn"), to.Strp("region"), to.Strp("000000"), opts, strings.NewReader("yes\n"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "--live")
+	}
+	assert.Equal(t, 0, len(awsc.ASG.DeletedAutoScalingGroups))
+
+	// The live release must exist in S3
+	opts.Live = "release-1"
+	assert.Error(t, doctor(awsc, to.Strp("arn"), to.Strp("region"), to.Strp("000000"), opts, strings.NewReader("yes\n")))
+
+	live := minimalRelease(t)
+	live.ReleaseID = to.Strp("release-1")
+	raw, _ := to.PrettyJSON(live)
+	awsc.S3.AddGetObject("000000/project/config/release-1/release", raw, nil)
+
+	assert.NoError(t, doctor(awsc, to.Strp("arn"), to.Strp("region"), to.Strp("000000"), opts, strings.NewReader("yes\n")))
+	assert.Equal(t, []string{orphanName}, awsc.ASG.DeletedAutoScalingGroups)
+	assert.NotContains(t, awsc.ASG.DeletedAutoScalingGroups, liveName)
+}
+
+func Test_DoctorOptionsFromArgs_Live(t *testing.T) {
+	opts, err := DoctorOptionsFromArgs([]string{"project", "config", "--fix", "--live", "release-1"})
+	assert.NoError(t, err)
+	assert.True(t, opts.Fix)
+	assert.Equal(t, "release-1", opts.Live)
+}
+
+func Test_Doctor_Carried_ASG_Is_Kept(t *testing.T) {
+	awsc, _, _, _ := mockDoctor(t)
+
+	// The live release is a partial release that kept the worker ASG of release-0
+	workerName := models.ReleaseServiceIDPrefix("project", "config", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)) + "worker"
+	workerASG := mocks.MakeMockASG(workerName, "project", "config", "worker", "release-0")
+	workerASG.LaunchConfigurationName = to.Strp(workerName)
+	awsc.ASG.AddASG(workerASG)
+	awsc.ASG.AddLaunchConfiguration(workerName)
+
+	live := minimalRelease(t)
+	live.ReleaseID = to.Strp("release-1")
+	live.Bucket = to.Strp("bucket")
+	live.AwsAccountID = to.Strp("000000")
+	live.ServicesToDeploy = []*string{to.Strp("web")}
+	live.Services["worker"] = &models.Service{CarriedASG: to.Strp(workerName)}
+	input, _ := to.PrettyJSON(live)
+
+	// The carried ASG is only in the stored release, not the execution input
+	awsc.S3.AddGetObject(*live.ReleasePath(), input, nil)
+	live.Services["worker"].CarriedASG = nil
+	input, _ = to.PrettyJSON(live)
+	awsc.SFN.AddDescribeExecution("arn1", "SUCCEEDED", input)
+
+	exam, err := examine(awsc, to.Strp("arn"), to.Strp("region"), to.Strp("000000"), &DoctorOptions{Project: "project", Config: "config"})
+	assert.NoError(t, err)
+
+	for _, f := range exam.Findings {
+		assert.NotEqual(t, workerName, f.Name)
+	}
+
+	// The release-0 web ASG is still an orphan
+	assert.Equal(t, "AutoScalingGroup", exam.Findings[0].Type)
+	assert.Contains(t, exam.Findings[0].Reason, "release-0")
+}
+
+func Test_Doctor_Draining_ASG_Is_Kept(t *testing.T) {
+	awsc, _, orphanName, _ := mockDoctor(t)
+
+	// The live release drains the release-0 ASG until its terminating hooks finish
+	live := minimalRelease(t)
+	live.ReleaseID = to.Strp("release-1")
+	live.Bucket = to.Strp("bucket")
+	live.AwsAccountID = to.Strp("000000")
+	live.TeardownStrategy = to.Strp("Drain")
+	live.DrainingASGs = []*string{to.Strp(orphanName)}
+	input, _ := to.PrettyJSON(live)
+
+	// The draining ASGs are only in the stored release, not the execution input
+	awsc.S3.AddGetObject(*live.ReleasePath(), input, nil)
+	live.DrainingASGs = nil
+	input, _ = to.PrettyJSON(live)
+	awsc.SFN.AddDescribeExecution("arn1", "SUCCEEDED", input)
+
+	exam, err := examine(awsc, to.Strp("arn"), to.Strp("region"), to.Strp("000000"), &DoctorOptions{Project: "project", Config: "config"})
+	assert.NoError(t, err)
+
+	for _, f := range exam.Findings {
+		assert.NotEqual(t, "AutoScalingGroup", f.Type)
+	}
+}
+
+func Test_Doctor_Launch_Templates(t *testing.T) {
+	awsc, liveName, _, leakedName := mockDoctor(t)
+	awsc.EC2.AddLaunchTemplate(liveName)
+	awsc.ASG.DescribeAutoScalingGroupsPageResp[0].Resp.AutoScalingGroups[0].LaunchTemplate = &autoscaling.LaunchTemplateSpecification{
+		LaunchTemplateName: to.Strp(liveName),
+	}
+
+	// ASG creation failed after the launch template was created
+	awsc.EC2.AddLaunchTemplate(leakedName)
+
+	exam, err := examine(awsc, to.Strp("arn"), to.Strp("region"), to.Strp("000000"), &DoctorOptions{Project: "project", Config: "config"})
+	assert.NoError(t, err)
+
+	lts := []string{}
+	for _, f := range exam.Findings {
+		if f.Type == "LaunchTemplate" {
+			lts = append(lts, f.Name)
+		}
+	}
+	assert.Equal(t, []string{leakedName}, lts)
+
+	opts := &DoctorOptions{Project: "project", Config: "config", Fix: true}
+	assert.NoError(t, doctor(awsc, to.Strp("arn"), to.Strp("region"), to.Strp("000000"), opts, strings.NewReader("yes\n")))
+	assert.Equal(t, []string{leakedName}, awsc.EC2.DeletedLaunchTemplates)
+}
@@ -2,10 +2,15 @@ package client
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
+	"io/ioutil"
+	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go/service/sfn"
 	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/deployer/models"
 	"github.com/coinbase/step/bifrost"
 	"github.com/coinbase/step/execution"
 	"github.com/coinbase/step/utils/to"
@@ -17,60 +22,291 @@ type FailedRelease struct {
 	AwsAccountID *string `json:"aws_account_id,omitempty"`
 	AwsRegion    *string `json:"aws_region,omitempty"`
 
+	ReleaseID   *string `json:"release_id,omitempty"`
 	ProjectName *string `json:"project_name,omitempty"`
 	ConfigName  *string `json:"config_name,omitempty"`
 
 	// Where the previous Catch Error should be located
 	Error *bifrost.ReleaseError `json:"error,omitempty"`
+
+	// Collected by CleanUpFailure before the ASGs were deleted
+	FailureReport *models.FailureReportSummary `json:"failure_report,omitempty"`
+}
+
+// FailuresOptions filter the failures listed
+type FailuresOptions struct {
+	Since   time.Duration
+	Project string
+	Config  string
+	State   string // FailureClean | FailureDirty
+	Limit   int
+	JSON    bool
+}
+
+// Failure is a single failed release
+type Failure struct {
+	ReleaseID    *string `json:"release_id,omitempty"`
+	ProjectName  *string `json:"project_name,omitempty"`
+	ConfigName   *string `json:"config_name,omitempty"`
+	ExecutionArn *string `json:"execution_arn,omitempty"`
+
+	// EndState is FailureClean or FailureDirty, FailedState is where the release failed
+	EndState    *string `json:"end_state,omitempty"`
+	FailedState *string `json:"failed_state,omitempty"`
+
+	ErrorType *string `json:"error_type,omitempty"`
+	Cause     *string `json:"cause,omitempty"`
+
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	Duration  *string    `json:"duration,omitempty"`
+
+	FailureReport *models.FailureReportSummary `json:"failure_report,omitempty"`
+}
+
+// States entered after a Catch, the state entered before the first of these is where the release failed
+var failurePathStates = map[string]bool{
+	"DetachForFailure":     true,
+	"WaitDetachForFailure": true,
+	"CleanUpFailure":       true,
+	"ReleaseLockFailure":   true,
+	"FailureClean":         true,
+	"FailureDirty":         true,
+}
+
+// FailuresOptionsFromArgs parses the `odin fails` flags
+func FailuresOptionsFromArgs(args []string) (*FailuresOptions, error) {
+	opts := FailuresOptions{}
+
+	fs := flag.NewFlagSet("fails", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	fs.DurationVar(&opts.Since, "since", 72*time.Hour, "how far back to look, e.g. 24h")
+	fs.StringVar(&opts.Project, "project", "", "only failures for this project")
+	fs.StringVar(&opts.Config, "config", "", "only failures for this config")
+	fs.StringVar(&opts.State, "state", "", "only failures ending in FailureClean or FailureDirty")
+	fs.IntVar(&opts.Limit, "limit", 0, "max number of failures to list (0 is no limit)")
+	fs.BoolVar(&opts.JSON, "json", false, "print failures as JSON")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &opts, nil
+}
+
+// Validate returns
+func (opts *FailuresOptions) Validate() error {
+	switch opts.State {
+	case "", "FailureClean", "FailureDirty":
+		// skip
+	default:
+		return fmt.Errorf("--state must be either 'FailureClean' or 'FailureDirty'")
+	}
+
+	if opts.Config != "" && opts.Project == "" {
+		return fmt.Errorf("--config requires --project")
+	}
+
+	if opts.Limit < 0 {
+		return fmt.Errorf("--limit must be positive")
+	}
+
+	return nil
+}
+
+// executionPrefix returns the prefix all executions matching the options start with
+func (opts *FailuresOptions) executionPrefix() string {
+	if opts.Project == "" {
+		return "deploy-"
+	}
+
+	pn := strings.Replace(opts.Project, "/", "-", -1)
+	if opts.Config == "" {
+		return fmt.Sprintf("deploy-%v-", pn)
+	}
+
+	return fmt.Sprintf("deploy-%v-%v-", pn, opts.Config)
 }
 
 // List the recent failures and their causes
-func Failures(step_fn *string) error {
+func Failures(step_fn *string, opts *FailuresOptions) error {
 	region, accountID := to.RegionAccount()
 
 	deployerARN := to.StepArn(region, accountID, step_fn)
 
 	awsc := &aws.ClientsStr{}
 
-	return failures(awsc.SFNClient(nil, nil, nil), deployerARN)
+	fails, err := failures(awsc.SFNClient(nil, nil, nil), deployerARN, opts)
+	if err != nil {
+		return err
+	}
+
+	if opts.JSON {
+		j, err := to.PrettyJSON(fails)
+		if err != nil {
+			return err
+		}
+		fmt.Println(j)
+		return nil
+	}
+
+	for _, f := range fails {
+		fmt.Println(failureStr(f))
+	}
+
+	return nil
 }
 
-func failures(sfnc aws.SFNAPI, arn *string) error {
-	execs, err := execution.ExecutionsAfter(sfnc, arn, to.Strp("FAILED"), time.Now().Add((-3*24)*time.Hour))
+func failures(sfnc aws.SFNAPI, arn *string, opts *FailuresOptions) ([]*Failure, error) {
+	execs, err := execution.ExecutionsAfter(sfnc, arn, to.Strp("FAILED"), time.Now().Add(-opts.Since))
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	fails := []*Failure{}
+	prefix := opts.executionPrefix()
+
 	for _, e := range execs {
-		sd, err := e.GetStateDetails(sfnc)
+		if opts.Limit > 0 && len(fails) >= opts.Limit {
+			break
+		}
+
+		if e.Name == nil || !strings.HasPrefix(*e.Name, prefix) {
+			continue
+		}
+
+		f, err := failure(sfnc, e)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		release := FailedRelease{}
-		if err := json.Unmarshal([]byte(*sd.LastOutput), &release); err != nil {
-			j, _ := to.PrettyJSON(*sd.LastOutput)
-			fmt.Println(j)
+		// The prefix can match other projects with dashes in their name, so check the release
+		if opts.Project != "" && to.Strs(f.ProjectName) != opts.Project {
 			continue
 		}
 
-		cause := ""
-		err_json := map[string]string{}
+		if opts.Config != "" && to.Strs(f.ConfigName) != opts.Config {
+			continue
+		}
+
+		if opts.State != "" && to.Strs(f.EndState) != opts.State {
+			continue
+		}
+
+		fails = append(fails, f)
+	}
+
+	return fails, nil
+}
+
+func failure(sfnc aws.SFNAPI, e *execution.Execution) (*Failure, error) {
+	sd, err := e.GetStateDetails(sfnc)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Failure{
+		ExecutionArn: e.ExecutionArn,
+		EndState:     sd.LastStateName,
+		StartedAt:    e.StartDate,
+	}
+
+	if e.StartDate != nil && e.StopDate != nil {
+		f.Duration = to.Strp(e.StopDate.Sub(*e.StartDate).Round(time.Second).String())
+	}
+
+	if f.FailedState, err = failedStateName(sfnc, e.ExecutionArn); err != nil {
+		return nil, err
+	}
+
+	if sd.LastOutput == nil {
+		return f, nil
+	}
+
+	release := FailedRelease{}
+	if err := json.Unmarshal([]byte(*sd.LastOutput), &release); err != nil {
+		// Output is not a release, return what we know
+		return f, nil
+	}
+
+	f.ReleaseID = release.ReleaseID
+	f.ProjectName = release.ProjectName
+	f.ConfigName = release.ConfigName
+	f.FailureReport = release.FailureReport
 
-		if release.Error != nil {
-			err = json.Unmarshal([]byte(*release.Error.Cause), &err_json)
+	if release.Error != nil {
+		f.ErrorType = release.Error.Error
+		f.Cause = release.Error.Cause
 
-			if err != nil {
-				fmt.Println(err)
-				cause = *release.Error.Cause
-			} else {
-				cause = err_json["errorMessage"]
+		// Lambda errors have their message wrapped in JSON
+		errJSON := map[string]string{}
+		if release.Error.Cause != nil && json.Unmarshal([]byte(*release.Error.Cause), &errJSON) == nil {
+			f.Cause = to.Strp(errJSON["errorMessage"])
+		}
+	}
+
+	return f, nil
+}
+
+// failedStateName walks the history backwards to find the state whose Catch started the failure path
+func failedStateName(sfnc aws.SFNAPI, arn *string) (*string, error) {
+	input := &sfn.GetExecutionHistoryInput{
+		ExecutionArn: arn,
+		ReverseOrder: to.Boolp(true),
+		MaxResults:   to.Int64p(100),
+	}
+
+	for {
+		out, err := sfnc.GetExecutionHistory(input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, he := range out.Events {
+			if he.StateEnteredEventDetails == nil || he.StateEnteredEventDetails.Name == nil {
+				continue
+			}
+
+			if !failurePathStates[*he.StateEnteredEventDetails.Name] {
+				return he.StateEnteredEventDetails.Name, nil
 			}
 		}
 
-		fmt.Println(fmt.Printf("%v -- %v -- %q", *sd.LastStateName, *e.Name, cause))
+		if out.NextToken == nil {
+			return nil, nil
+		}
+
+		input.NextToken = out.NextToken
 	}
+}
 
-	return nil
+func failureStr(f *Failure) string {
+	RED := "\x1b[0;31m"
+	NC := "\x1b[0m" // No Color
+
+	endState := to.Strs(f.EndState)
+	if endState == "FailureDirty" {
+		// FailureDirty always needs someone to look at it
+		endState = fmt.Sprintf("%v%v (needs action)%v", RED, endState, NC)
+	}
+
+	lines := []string{
+		fmt.Sprintf("%v -- %v -- %v", endState, to.Strs(f.ReleaseID), to.Strs(f.ExecutionArn)),
+		fmt.Sprintf("  Failed in %v with %v: %q", to.Strs(f.FailedState), to.Strs(f.ErrorType), to.Strs(f.Cause)),
+	}
+
+	if f.StartedAt != nil {
+		lines = append(lines, fmt.Sprintf("  Started %v, took %v", f.StartedAt.Format(time.RFC3339), to.Strs(f.Duration)))
+	}
+
+	if f.FailureReport != nil {
+		lines = append(lines, fmt.Sprintf("  Report: %v", to.Strs(f.FailureReport.Summary)))
+		lines = append(lines, fmt.Sprintf("  Path:   %v", to.Strs(f.FailureReport.Path)))
+	}
+
+	return strings.Join(lines, "\n")
 }
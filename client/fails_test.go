@@ -0,0 +1,150 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func enteredEvent(name string) *sfn.HistoryEvent {
+	return &sfn.HistoryEvent{
+		Type:                     to.Strp("TaskStateEntered"),
+		StateEnteredEventDetails: &sfn.StateEnteredEventDetails{Name: to.Strp(name)},
+	}
+}
+
+func exitedEvent(name string, output string) *sfn.HistoryEvent {
+	return &sfn.HistoryEvent{
+		Type:                    to.Strp("TaskStateExited"),
+		StateExitedEventDetails: &sfn.StateExitedEventDetails{Name: to.Strp(name), Output: to.Strp(output)},
+	}
+}
+
+func mockFailedExecutions() *mocks.MockClients {
+	awsc := mocks.MockAWS()
+	start := time.Now().Add(-1 * time.Hour)
+	stop := start.Add(5 * time.Minute)
+
+	awsc.SFN.ListExecutionsResp = &sfn.ListExecutionsOutput{
+		Executions: []*sfn.ExecutionListItem{
+			&sfn.ExecutionListItem{
+				Name:         to.Strp("deploy-project-config-1"),
+				ExecutionArn: to.Strp("arn1"),
+				StartDate:    &start,
+				StopDate:     &stop,
+			},
+			&sfn.ExecutionListItem{
+				Name:         to.Strp("deploy-other-config-2"),
+				ExecutionArn: to.Strp("arn2"),
+				StartDate:    &start,
+				StopDate:     &stop,
+			},
+		},
+	}
+
+	// History is in reverse order
+	awsc.SFN.AddExecutionHistory("arn1",
+		enteredEvent("FailureDirty"),
+		exitedEvent("CleanUpFailure", `{
+			"release_id": "release-1",
+			"project_name": "project",
+			"config_name": "config",
+			"error": {"Error": "CleanUpError", "Cause": "{\"errorMessage\": \"cant delete\"}"},
+			"failure_report": {"path": "s3://bucket/report", "summary": "web: 0 healthy"}
+		}`),
+		enteredEvent("CleanUpFailure"),
+		enteredEvent("WaitDetachForFailure"),
+		enteredEvent("DetachForFailure"),
+		enteredEvent("CheckHealthy"),
+	)
+
+	awsc.SFN.AddExecutionHistory("arn2",
+		enteredEvent("FailureClean"),
+		exitedEvent("Validate", `{
+			"release_id": "release-2",
+			"project_name": "other",
+			"config_name": "config",
+			"error": {"Error": "BadReleaseError", "Cause": "bad"}
+		}`),
+		enteredEvent("Validate"),
+	)
+
+	return awsc
+}
+
+func Test_Failures(t *testing.T) {
+	awsc := mockFailedExecutions()
+
+	opts, err := FailuresOptionsFromArgs([]string{})
+	assert.NoError(t, err)
+
+	fails, err := failures(awsc.SFN, to.Strp("arn"), opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(fails))
+
+	dirty := fails[0]
+	assert.Equal(t, "release-1", *dirty.ReleaseID)
+	assert.Equal(t, "arn1", *dirty.ExecutionArn)
+	assert.Equal(t, "FailureDirty", *dirty.EndState)
+	assert.Equal(t, "CheckHealthy", *dirty.FailedState)
+	assert.Equal(t, "CleanUpError", *dirty.ErrorType)
+	assert.Equal(t, "cant delete", *dirty.Cause)
+	assert.Equal(t, "5m0s", *dirty.Duration)
+	assert.Equal(t, "s3://bucket/report", *dirty.FailureReport.Path)
+
+	clean := fails[1]
+	assert.Equal(t, "FailureClean", *clean.EndState)
+	assert.Equal(t, "Validate", *clean.FailedState)
+	assert.Equal(t, "bad", *clean.Cause)
+
+	assert.Contains(t, failureStr(dirty), "needs action")
+	assert.NotContains(t, failureStr(clean), "needs action")
+}
+
+func Test_Failures_Filters(t *testing.T) {
+	awsc := mockFailedExecutions()
+
+	opts, err := FailuresOptionsFromArgs([]string{"--project", "project", "--config", "config"})
+	assert.NoError(t, err)
+	fails, err := failures(awsc.SFN, to.Strp("arn"), opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(fails))
+	assert.Equal(t, "release-1", *fails[0].ReleaseID)
+
+	opts, err = FailuresOptionsFromArgs([]string{"--state", "FailureClean"})
+	assert.NoError(t, err)
+	fails, err = failures(awsc.SFN, to.Strp("arn"), opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(fails))
+	assert.Equal(t, "release-2", *fails[0].ReleaseID)
+
+	opts, err = FailuresOptionsFromArgs([]string{"--limit", "1"})
+	assert.NoError(t, err)
+	fails, err = failures(awsc.SFN, to.Strp("arn"), opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(fails))
+
+	opts, err = FailuresOptionsFromArgs([]string{"--since", "10m"})
+	assert.NoError(t, err)
+	fails, err = failures(awsc.SFN, to.Strp("arn"), opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(fails))
+}
+
+func Test_FailuresOptionsFromArgs_Errors(t *testing.T) {
+	_, err := FailuresOptionsFromArgs([]string{"--state", "Success"})
+	assert.Error(t, err)
+
+	_, err = FailuresOptionsFromArgs([]string{"--config", "config"})
+	assert.Error(t, err)
+
+	_, err = FailuresOptionsFromArgs([]string{"--limit", "-1"})
+	assert.Error(t, err)
+
+	_, err = FailuresOptionsFromArgs([]string{"--unknown"})
+	assert.Error(t, err)
+}
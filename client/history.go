@@ -0,0 +1,299 @@
+package client
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/deployer/models"
+	"github.com/coinbase/step/aws/s3"
+	"github.com/coinbase/step/bifrost"
+	"github.com/coinbase/step/execution"
+	"github.com/coinbase/step/utils/is"
+	"github.com/coinbase/step/utils/to"
+)
+
+// HistoryOptions select the releases listed
+type HistoryOptions struct {
+	Project string
+	Config  string
+	Since   time.Duration
+	Limit   int
+	JSON    bool
+	Diff    []string // Two release IDs to compare, oldest first
+}
+
+// HistoryEntry is a single past release of a project-config
+type HistoryEntry struct {
+	ReleaseID     *string           `json:"release_id,omitempty"`
+	CreatedAt     *time.Time        `json:"created_at,omitempty"`
+	ExecutionArn  *string           `json:"execution_arn,omitempty"`
+	State         *string           `json:"state,omitempty"`
+	Duration      *string           `json:"duration,omitempty"`
+	Image         *string           `json:"ami,omitempty"`
+	InstanceTypes map[string]string `json:"instance_types,omitempty"`
+	DeployedBy    *string           `json:"deployed_by,omitempty"`
+
+	release *models.Release
+}
+
+// HistoryOptionsFromArgs parses `odin history <project> <config> [flags]`
+func HistoryOptionsFromArgs(args []string) (*HistoryOptions, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("history requires <project> <config>")
+	}
+
+	opts := HistoryOptions{Project: args[0], Config: args[1]}
+	var diff string
+
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	fs.DurationVar(&opts.Since, "since", 30*24*time.Hour, "how far back to look, e.g. 168h")
+	fs.IntVar(&opts.Limit, "limit", 0, "max number of releases to list (0 is no limit)")
+	fs.BoolVar(&opts.JSON, "json", false, "print releases as JSON")
+	fs.StringVar(&diff, "diff", "", "compare two releases: --diff <id1> <id2>")
+
+	if err := fs.Parse(args[2:]); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case diff != "" && fs.NArg() == 1:
+		opts.Diff = []string{diff, fs.Arg(0)}
+	case diff != "":
+		return nil, fmt.Errorf("--diff requires two release IDs")
+	case fs.NArg() > 0:
+		return nil, fmt.Errorf("unexpected arguments %v", fs.Args())
+	}
+
+	if opts.Limit < 0 {
+		return nil, fmt.Errorf("--limit must be positive")
+	}
+
+	return &opts, nil
+}
+
+// History lists the past releases for a project-config
+func History(step_fn *string, opts *HistoryOptions) error {
+	region, accountID := to.RegionAccount()
+
+	deployerARN := to.StepArn(region, accountID, step_fn)
+
+	awsc := &aws.ClientsStr{}
+
+	entries, err := history(awsc, deployerARN, opts)
+	if err != nil {
+		return err
+	}
+
+	if len(opts.Diff) == 2 {
+		diffs, err := historyDiff(entries, opts.Diff[0], opts.Diff[1])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Differences from %v to %v:\n", opts.Diff[0], opts.Diff[1])
+		for _, d := range diffs {
+			fmt.Printf("  %v\n", d)
+		}
+		return nil
+	}
+
+	if opts.JSON {
+		j, err := to.PrettyJSON(entries)
+		if err != nil {
+			return err
+		}
+		fmt.Println(j)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RELEASE\tCREATED\tSTATE\tDURATION\tAMI\tINSTANCE TYPES\tDEPLOYED BY")
+	for _, e := range entries {
+		fmt.Fprintln(w, historyEntryStr(e))
+	}
+
+	return w.Flush()
+}
+
+func history(awsc aws.Clients, arn *string, opts *HistoryOptions) ([]*HistoryEntry, error) {
+	sfnc := awsc.SFNClient(nil, nil, nil)
+
+	execs, err := execution.ExecutionsAfter(sfnc, arn, nil, time.Now().Add(-opts.Since))
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := (&bifrost.Release{ProjectName: &opts.Project, ConfigName: &opts.Config}).ExecutionPrefix()
+
+	entries := []*HistoryEntry{}
+	for _, e := range execs {
+		// When diffing the limit is ignored so older releases can be found
+		if len(opts.Diff) == 0 && opts.Limit > 0 && len(entries) >= opts.Limit {
+			break
+		}
+
+		if e.Name == nil || !strings.HasPrefix(*e.Name, prefix) {
+			continue
+		}
+
+		entry, err := historyEntry(awsc, e)
+		if err != nil {
+			return nil, err
+		}
+
+		// The prefix can match other projects with dashes in their name, so check the release
+		if r := entry.release; r != nil && (to.Strs(r.ProjectName) != opts.Project || to.Strs(r.ConfigName) != opts.Config) {
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func historyEntry(awsc aws.Clients, e *execution.Execution) (*HistoryEntry, error) {
+	sfnc := awsc.SFNClient(nil, nil, nil)
+
+	entry := &HistoryEntry{
+		ExecutionArn: e.ExecutionArn,
+		State:        e.Status,
+	}
+
+	if e.StartDate != nil && e.StopDate != nil {
+		entry.Duration = to.Strp(e.StopDate.Sub(*e.StartDate).Round(time.Second).String())
+	}
+
+	if to.Strs(e.Status) == "FAILED" {
+		// The end state tells if it was FailureClean or FailureDirty
+		sd, err := e.GetStateDetails(sfnc)
+		if err != nil {
+			return nil, err
+		}
+
+		if sd.LastStateName != nil {
+			entry.State = sd.LastStateName
+		}
+	}
+
+	out, err := sfnc.DescribeExecution(&sfn.DescribeExecutionInput{ExecutionArn: e.ExecutionArn})
+	if err != nil {
+		return nil, err
+	}
+
+	if out.Input == nil {
+		return entry, nil
+	}
+
+	var release models.Release
+	if err := json.Unmarshal([]byte(*out.Input), &release); err != nil {
+		// Input is not a release, return what we know
+		return entry, nil
+	}
+
+	// The release uploaded to S3 is what was validated, prefer it to the execution input
+	if !is.EmptyStr(release.Bucket) && !is.EmptyStr(release.AwsAccountID) && !is.EmptyStr(release.ReleaseID) {
+		var s3Release models.Release
+		if err := s3.GetStruct(awsc.S3Client(nil, nil, nil), release.Bucket, release.ReleasePath(), &s3Release); err == nil {
+			release = s3Release
+		}
+	}
+
+	entry.release = &release
+	entry.ReleaseID = release.ReleaseID
+	entry.CreatedAt = release.CreatedAt
+	entry.Image = release.ResolvedImage()
+	entry.InstanceTypes = map[string]string{}
+
+	for name, service := range release.Services {
+		if service != nil && service.InstanceType != nil {
+			entry.InstanceTypes[name] = *service.InstanceType
+		}
+	}
+
+	if by, ok := release.Metadata["deployed_by"]; ok {
+		entry.DeployedBy = to.Strp(by)
+	}
+
+	return entry, nil
+}
+
+// historyDiff compares two releases with the safe release checks, previous is the first ID
+func historyDiff(entries []*HistoryEntry, previousID string, releaseID string) ([]string, error) {
+	var previous, release *models.Release
+	for _, e := range entries {
+		switch to.Strs(e.ReleaseID) {
+		case previousID:
+			previous = e.release
+		case releaseID:
+			release = e.release
+		}
+	}
+
+	if previous == nil {
+		return nil, fmt.Errorf("release %v not found, try a larger --since", previousID)
+	}
+
+	if release == nil {
+		return nil, fmt.Errorf("release %v not found, try a larger --since", releaseID)
+	}
+
+	// Set Defaults for comparison
+	for _, r := range []*models.Release{previous, release} {
+		r.Release.SetDefaults(r.AwsRegion, r.AwsAccountID, "coinbase-odin-")
+		r.SetDefaults()
+	}
+
+	diffs := []string{}
+
+	if to.Strs(previous.ResolvedImage()) != to.Strs(release.ResolvedImage()) {
+		diffs = append(diffs, fmt.Sprintf("AMI different previous release has %v, requested %v", to.Strs(previous.ResolvedImage()), to.Strs(release.ResolvedImage())))
+	}
+
+	if to.Strs(previous.UserDataSHA256) != to.Strs(release.UserDataSHA256) {
+		diffs = append(diffs, "UserData different")
+	}
+
+	if sre := release.DiffSafeRelease(previous); sre != nil {
+		diffs = append(diffs, sre.Differences()...)
+	}
+
+	if len(diffs) == 0 {
+		diffs = append(diffs, "No differences")
+	}
+
+	return diffs, nil
+}
+
+func historyEntryStr(e *HistoryEntry) string {
+	created := ""
+	if e.CreatedAt != nil {
+		created = e.CreatedAt.Format(time.RFC3339)
+	}
+
+	types := []string{}
+	for name, it := range e.InstanceTypes {
+		types = append(types, fmt.Sprintf("%v:%v", name, it))
+	}
+	sort.Strings(types)
+
+	return strings.Join([]string{
+		to.Strs(e.ReleaseID),
+		created,
+		to.Strs(e.State),
+		to.Strs(e.Duration),
+		to.Strs(e.Image),
+		strings.Join(types, ","),
+		to.Strs(e.DeployedBy),
+	}, "\t")
+}
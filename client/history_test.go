@@ -0,0 +1,137 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockHistory(t *testing.T) *mocks.MockClients {
+	awsc := mocks.MockAWS()
+	start := time.Now().Add(-1 * time.Hour)
+	stop := start.Add(10 * time.Minute)
+
+	awsc.SFN.ListExecutionsResp = &sfn.ListExecutionsOutput{
+		Executions: []*sfn.ExecutionListItem{
+			&sfn.ExecutionListItem{
+				Name:         to.Strp("deploy-project-config-2"),
+				ExecutionArn: to.Strp("arn2"),
+				Status:       to.Strp("SUCCEEDED"),
+				StartDate:    &start,
+				StopDate:     &stop,
+			},
+			&sfn.ExecutionListItem{
+				Name:         to.Strp("deploy-project-config-1"),
+				ExecutionArn: to.Strp("arn1"),
+				Status:       to.Strp("FAILED"),
+				StartDate:    &start,
+				StopDate:     &stop,
+			},
+			&sfn.ExecutionListItem{
+				Name:         to.Strp("deploy-project-other-3"),
+				ExecutionArn: to.Strp("arn3"),
+				Status:       to.Strp("SUCCEEDED"),
+				StartDate:    &start,
+			},
+		},
+	}
+
+	r1 := minimalRelease(t)
+	r1.ReleaseID = to.Strp("release-1")
+	prepareRelease(r1, to.Strp("region"), to.Strp("account"))
+	r1.ReleaseID = to.Strp("release-1")
+	r1.Metadata["deployed_by"] = "alice"
+
+	r2 := minimalRelease(t)
+	prepareRelease(r2, to.Strp("region"), to.Strp("account"))
+	r2.ReleaseID = to.Strp("release-2")
+	r2.Image = to.Strp("ami-654321")
+	r2.Services["web"].InstanceType = to.Strp("c5.large")
+	r2.Metadata["deployed_by"] = "bob"
+
+	// Release 1 is only in the execution input, release 2 is also in S3
+	j1, _ := to.PrettyJSON(r1)
+	awsc.SFN.AddDescribeExecution("arn1", "FAILED", j1)
+
+	input2 := *r2
+	input2.Metadata = map[string]string{}
+	j2, _ := to.PrettyJSON(input2)
+	awsc.SFN.AddDescribeExecution("arn2", "SUCCEEDED", j2)
+
+	raw, _ := to.PrettyJSON(r2)
+	awsc.S3.AddGetObject(*r2.ReleasePath(), raw, nil)
+
+	awsc.SFN.AddExecutionHistory("arn1", enteredEvent("FailureClean"))
+
+	return awsc
+}
+
+func Test_History(t *testing.T) {
+	awsc := mockHistory(t)
+
+	opts, err := HistoryOptionsFromArgs([]string{"project", "config"})
+	assert.NoError(t, err)
+
+	entries, err := history(awsc, to.Strp("arn"), opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(entries))
+
+	assert.Equal(t, "release-2", *entries[0].ReleaseID)
+	assert.Equal(t, "SUCCEEDED", *entries[0].State)
+	assert.Equal(t, "10m0s", *entries[0].Duration)
+	assert.Equal(t, "ami-654321", *entries[0].Image)
+	assert.Equal(t, "c5.large", entries[0].InstanceTypes["web"])
+	assert.Equal(t, "bob", *entries[0].DeployedBy) // From S3 not the execution input
+
+	assert.Equal(t, "release-1", *entries[1].ReleaseID)
+	assert.Equal(t, "FailureClean", *entries[1].State)
+	assert.Equal(t, "alice", *entries[1].DeployedBy)
+
+	assert.Contains(t, historyEntryStr(entries[0]), "web:c5.large")
+}
+
+func Test_History_Limit(t *testing.T) {
+	awsc := mockHistory(t)
+
+	opts, err := HistoryOptionsFromArgs([]string{"project", "config", "--limit", "1"})
+	assert.NoError(t, err)
+
+	entries, err := history(awsc, to.Strp("arn"), opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(entries))
+}
+
+func Test_History_Diff(t *testing.T) {
+	awsc := mockHistory(t)
+
+	opts, err := HistoryOptionsFromArgs([]string{"project", "config", "--diff", "release-1", "release-2"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"release-1", "release-2"}, opts.Diff)
+
+	entries, err := history(awsc, to.Strp("arn"), opts)
+	assert.NoError(t, err)
+
+	diffs, err := historyDiff(entries, "release-1", "release-2")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(diffs))
+	assert.Contains(t, diffs[0], "AMI different previous release has ami-123456, requested ami-654321")
+	assert.Contains(t, diffs[1], "InstanceType different previous release has t2.small, requested c5.large")
+
+	_, err = historyDiff(entries, "release-1", "release-unknown")
+	assert.Error(t, err)
+}
+
+func Test_HistoryOptionsFromArgs_Errors(t *testing.T) {
+	_, err := HistoryOptionsFromArgs([]string{"project"})
+	assert.Error(t, err)
+
+	_, err = HistoryOptionsFromArgs([]string{"project", "config", "--diff", "release-1"})
+	assert.Error(t, err)
+
+	_, err = HistoryOptionsFromArgs([]string{"project", "config", "extra"})
+	assert.Error(t, err)
+}
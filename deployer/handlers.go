@@ -3,6 +3,7 @@ package deployer
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/coinbase/odin/aws"
 	"github.com/coinbase/odin/deployer/models"
@@ -14,6 +15,9 @@ import (
 // DeployHandler function type
 type DeployHandler func(context.Context, *models.Release) (*models.Release, error)
 
+// SweepHandler function type
+type SweepHandler func(context.Context, *models.Sweep) (*models.Sweep, error)
+
 // Errors
 type DetachError struct {
 	Cause string
@@ -23,6 +27,14 @@ func (e DetachError) Error() string {
 	return fmt.Sprintf("DetachError: %v", e.Cause)
 }
 
+type DrainError struct {
+	Cause string
+}
+
+func (e DrainError) Error() string {
+	return fmt.Sprintf("DrainError: %v", e.Cause)
+}
+
 ////////////
 // HANDLERS
 ////////////
@@ -75,16 +87,38 @@ func ValidateResources(awsc aws.Clients) DeployHandler {
 			awsc.ALBClient(release.AwsRegion, release.AwsAccountID, assumedRole),
 			awsc.IAMClient(release.AwsRegion, release.AwsAccountID, assumedRole),
 			awsc.SNSClient(release.AwsRegion, release.AwsAccountID, assumedRole),
+			awsc.SQSClient(release.AwsRegion, release.AwsAccountID, assumedRole),
+			awsc.S3Client(release.AwsRegion, nil, nil),
 		)
 
 		if err != nil {
 			return nil, &errors.BadReleaseError{err.Error()}
 		}
 
+		config, err := models.FetchOdinConfig(awsc.S3Client(release.AwsRegion, nil, nil), release.Bucket)
+		if err != nil {
+			return nil, &errors.BadReleaseError{err.Error()}
+		}
+
+		// AMIs shared from a trusted account are checked with the tags in that account
+		if err := release.FetchSharedImage(resources, config, func(accountID *string) aws.EC2API {
+			return awsc.EC2Client(release.AwsRegion, accountID, assumedRole)
+		}); err != nil {
+			return nil, &errors.BadReleaseError{err.Error()}
+		}
+
 		if err := release.ValidateResources(resources); err != nil {
 			return nil, &errors.BadReleaseError{err.Error()}
 		}
 
+		if err := release.ValidateImagePolicy(resources, config, time.Now()); err != nil {
+			return nil, &errors.BadReleaseError{err.Error()}
+		}
+
+		if err := release.ValidateCarriedServices(awsc.S3Client(release.AwsRegion, nil, nil), resources, config); err != nil {
+			return nil, &errors.BadReleaseError{err.Error()}
+		}
+
 		// If this flag is set Odin will fail a deploy if previous Release is dangerously different
 		if release.SafeRelease {
 			if err := release.ValidateSafeRelease(
@@ -97,6 +131,21 @@ func ValidateResources(awsc aws.Clients) DeployHandler {
 
 		release.UpdateWithResources(resources)
 
+		pricing, err := models.FetchPricingTable(awsc.S3Client(release.AwsRegion, nil, nil), release.Bucket)
+		if err != nil {
+			return nil, &errors.BadReleaseError{err.Error()}
+		}
+
+		// Estimated after UpdateWithResources so services are at their current desired capacity
+		if err := release.ValidateCost(awsc.S3Client(release.AwsRegion, nil, nil), resources, pricing, config); err != nil {
+			return nil, &errors.BadReleaseError{err.Error()}
+		}
+
+		// Record the resolved AMI and ASGs so the stored release points at exactly what was deployed
+		if err := release.StoreDeployState(awsc.S3Client(release.AwsRegion, nil, nil)); err != nil {
+			return nil, &errors.BadReleaseError{err.Error()}
+		}
+
 		return release, nil
 	}
 }
@@ -117,6 +166,7 @@ func Deploy(awsc aws.Clients) DeployHandler {
 		if err := release.CreateResources(
 			awsc.ASGClient(release.AwsRegion, release.AwsAccountID, assumedRole),
 			awsc.CWClient(release.AwsRegion, release.AwsAccountID, assumedRole),
+			awsc.EC2Client(release.AwsRegion, release.AwsAccountID, assumedRole),
 		); err != nil {
 			return nil, &errors.DeployError{err.Error()}
 		}
@@ -138,6 +188,7 @@ func CheckHealthy(awsc aws.Clients) DeployHandler {
 			awsc.ASGClient(release.AwsRegion, release.AwsAccountID, assumedRole),
 			awsc.ELBClient(release.AwsRegion, release.AwsAccountID, assumedRole),
 			awsc.ALBClient(release.AwsRegion, release.AwsAccountID, assumedRole),
+			awsc.S3Client(release.AwsRegion, nil, nil),
 		)
 
 		if err != nil {
@@ -151,6 +202,22 @@ func CheckHealthy(awsc aws.Clients) DeployHandler {
 			}
 		}
 
+		// Start the next wave once the services it depends on are healthy
+		if len(release.ReadyServices()) > 0 {
+			if err := release.SetDefaultsWithUserData(awsc.S3Client(release.AwsRegion, nil, nil)); err != nil {
+				return nil, &errors.HaltError{err.Error()}
+			}
+
+			// A retry would find a half created ASG, so halt to clean up
+			if err := release.CreateReadyResources(
+				awsc.ASGClient(release.AwsRegion, release.AwsAccountID, assumedRole),
+				awsc.CWClient(release.AwsRegion, release.AwsAccountID, assumedRole),
+				awsc.EC2Client(release.AwsRegion, release.AwsAccountID, assumedRole),
+			); err != nil {
+				return nil, &errors.HaltError{err.Error()}
+			}
+		}
+
 		return release, nil
 	}
 }
@@ -177,14 +244,34 @@ func DetachForSuccess(awsc aws.Clients) DeployHandler {
 
 // CleanUpSuccess deleted the old resources
 func CleanUpSuccess(awsc aws.Clients) DeployHandler {
+	return cleanUpSuccess(awsc, false)
+}
+
+// ForceCleanUpSuccess deletes the old resources that did not drain in time
+func ForceCleanUpSuccess(awsc aws.Clients) DeployHandler {
+	return cleanUpSuccess(awsc, true)
+}
+
+func cleanUpSuccess(awsc aws.Clients, force bool) DeployHandler {
 	return func(ctx context.Context, release *models.Release) (*models.Release, error) {
 		release.SetDefaults() // Wire up non-serialized relationships
 
-		if err := release.SuccessfulTearDown(
+		teardown := release.SuccessfulTearDown
+		if force {
+			teardown = release.ForceSuccessfulTearDown
+		}
+
+		if err := teardown(
 			awsc.ASGClient(release.AwsRegion, release.AwsAccountID, assumedRole),
 			awsc.CWClient(release.AwsRegion, release.AwsAccountID, assumedRole),
+			awsc.EC2Client(release.AwsRegion, release.AwsAccountID, assumedRole),
 		); err != nil {
-			return nil, &errors.CleanUpError{err.Error()}
+			switch err.(type) {
+			case models.DrainError:
+				return nil, &DrainError{err.Error()}
+			default:
+				return nil, &errors.CleanUpError{err.Error()}
+			}
 		}
 
 		locker := dynamodb.NewDynamoDBLocker(awsc.DynamoDBClient(nil, nil, nil))
@@ -238,9 +325,20 @@ func CleanUpFailure(awsc aws.Clients) DeployHandler {
 
 		release.Success = to.Boolp(false) // Quickly Mark Failure
 
+		// Collect why the instances failed before the evidence is torn down
+		// This is best effort and must never stop the clean up
+		if err := release.CreateFailureReport(
+			awsc.ASGClient(release.AwsRegion, release.AwsAccountID, assumedRole),
+			awsc.EC2Client(release.AwsRegion, release.AwsAccountID, assumedRole),
+			awsc.S3Client(release.AwsRegion, nil, nil),
+		); err != nil {
+			fmt.Printf("IGNORED: %v \n", err)
+		}
+
 		if err := release.UnsuccessfulTearDown(
 			awsc.ASGClient(release.AwsRegion, release.AwsAccountID, assumedRole),
 			awsc.CWClient(release.AwsRegion, release.AwsAccountID, assumedRole),
+			awsc.EC2Client(release.AwsRegion, release.AwsAccountID, assumedRole),
 		); err != nil {
 			switch err.(type) {
 			case models.DetachError:
@@ -250,6 +348,13 @@ func CleanUpFailure(awsc aws.Clients) DeployHandler {
 			}
 		}
 
+		// The previous ASGs can be gone or fail to resume, which must not leave the release dirty
+		if err := release.ResumePreviousScaling(
+			awsc.ASGClient(release.AwsRegion, release.AwsAccountID, assumedRole),
+		); err != nil {
+			fmt.Printf("IGNORED: %v \n", err)
+		}
+
 		return release, nil
 	}
 }
@@ -273,6 +378,33 @@ func ReleaseLockFailure(awsc aws.Clients) DeployHandler {
 	}
 }
 
+// Sweep deletes launch configurations, launch templates and alarms left behind without an ASG
+// It is not part of the state machine, it is meant to be invoked on a schedule
+func Sweep(awsc aws.Clients) SweepHandler {
+	return func(ctx context.Context, sweep *models.Sweep) (*models.Sweep, error) {
+		region, account := to.AwsRegionAccountFromContext(ctx)
+		sweep.SetDefaults(region, account)
+
+		if err := sweep.Validate(); err != nil {
+			return nil, err
+		}
+
+		if err := sweep.Run(
+			awsc.ASGClient(sweep.AwsRegion, sweep.AwsAccountID, assumedRole),
+			awsc.CWClient(sweep.AwsRegion, sweep.AwsAccountID, assumedRole),
+			awsc.EC2Client(sweep.AwsRegion, sweep.AwsAccountID, assumedRole),
+			time.Now(),
+		); err != nil {
+			return nil, err
+		}
+
+		fmt.Printf("Sweep (dry run %v): %v launch configurations %v, %v launch templates %v, %v alarms %v\n",
+			*sweep.DryRun, len(sweep.LaunchConfigurations), sweep.LaunchConfigurations, len(sweep.LaunchTemplates), sweep.LaunchTemplates, len(sweep.Alarms), sweep.Alarms)
+
+		return sweep, nil
+	}
+}
+
 func getLockTableNameFromContext(ctx context.Context, postfix string) string {
 	_, _, lambdaName := to.AwsRegionAccountLambdaNameFromContext(ctx)
 	return fmt.Sprintf("%s%s", lambdaName, postfix)
@@ -1,6 +1,7 @@
 package deployer
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -141,3 +142,57 @@ func Test_CheckHealthy_Terming(t *testing.T) {
 	_, err := CheckHealthy(awsc)(nil, release)
 	assert.Error(t, err)
 }
+
+// Test the Sweep task only reports in dry run, and deletes otherwise
+func Test_Sweep(t *testing.T) {
+	awsc := mocks.MockAWS()
+	awsc.ASG.AddASG(mocks.MakeMockASG("project-config-2020-01-03T00-00-00Z-web", "project", "config", "web", "live"))
+	awsc.ASG.AddLaunchConfiguration("project-config-2020-01-02T00-00-00Z-web")
+
+	sweep, err := Sweep(awsc)(context.Background(), &models.Sweep{AwsRegion: to.Strp("region"), AwsAccountID: to.Strp("account")})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"project-config-2020-01-02T00-00-00Z-web"}, sweep.LaunchConfigurations)
+	assert.Equal(t, 0, len(awsc.ASG.DeletedLaunchConfigurations))
+
+	sweep, err = Sweep(awsc)(context.Background(), &models.Sweep{AwsRegion: to.Strp("region"), AwsAccountID: to.Strp("account"), DryRun: to.Boolp(false)})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"project-config-2020-01-02T00-00-00Z-web"}, awsc.ASG.DeletedLaunchConfigurations)
+
+	_, err = Sweep(awsc)(context.Background(), &models.Sweep{AwsRegion: to.Strp("region"), AwsAccountID: to.Strp("account"), OlderThan: to.Intp(1)})
+	assert.Error(t, err)
+}
+
+// Test the new ASGs are torn down even if the previous ASG cannot resume scaling
+func Test_CleanUpFailure_IgnoresResumeErrors(t *testing.T) {
+	release := models.MockRelease(t)
+	models.MockPrepareRelease(release)
+	release.Services["web"].SuspendPreviousScaling = true
+	release.Services["web"].Resources = &models.ServiceResourceNames{PrevASG: to.Strp("web-old")}
+	release.Services["web"].SuspendedProcesses = []*string{to.Strp("AZRebalance")}
+
+	awsc := mocks.MockAWS()
+	awsc.ASG.AddASG(mocks.MakeMockASG("web-new", *release.ProjectName, *release.ConfigName, "web", *release.ReleaseID))
+	awsc.ASG.ResumeProcessesError = fmt.Errorf("ValidationError: AutoScalingGroup name not found")
+
+	_, err := CleanUpFailure(awsc)(nil, release)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"web-new"}, awsc.ASG.DeletedAutoScalingGroups)
+}
+
+// Test the previous ASGs that did not drain are deleted and the release still succeeds
+func Test_ForceCleanUpSuccess_DeletesDrainingASGs(t *testing.T) {
+	release := models.MockRelease(t)
+	release.TeardownStrategy = to.Strp("Drain")
+	models.MockPrepareRelease(release)
+
+	awsc := models.MockAwsClients(release)
+
+	_, err := CleanUpSuccess(awsc)(context.Background(), release)
+	assert.IsType(t, &DrainError{}, err)
+	assert.Equal(t, 0, len(awsc.ASG.DeletedAutoScalingGroups))
+
+	out, err := ForceCleanUpSuccess(awsc)(context.Background(), release)
+	assert.NoError(t, err)
+	assert.True(t, *out.Success)
+	assert.Equal(t, 1, len(awsc.ASG.DeletedAutoScalingGroups))
+}
@@ -1,6 +1,7 @@
 package deployer
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -8,6 +9,7 @@ import (
 
 	"github.com/coinbase/odin/aws/mocks"
 	"github.com/coinbase/odin/deployer/models"
+	"github.com/coinbase/step/machine"
 	"github.com/coinbase/step/utils/to"
 	"github.com/stretchr/testify/assert"
 )
@@ -388,3 +390,70 @@ func Test_Execution_CleanupSuccess_Detach_SkipDetach(t *testing.T) {
 
 	assertSuccessfulExecutionWithAWS(t, release, maws)
 }
+
+func Test_Execution_CleanupSuccess_Drain_HoldsLock(t *testing.T) {
+	release := models.MockRelease(t)
+	release.TeardownStrategy = to.Strp("Drain")
+
+	maws := models.MockAwsClients(release)
+
+	stateMachine, err := StateMachine()
+	assert.NoError(t, err)
+
+	tm := CreateTaskFunctinons(maws)
+	cleanUpSuccess := (*tm)["CleanUpSuccess"].(DeployHandler)
+
+	var secondExec *machine.Execution
+	(*tm)["CleanUpSuccess"] = func(ctx context.Context, release *models.Release) (*models.Release, error) {
+		out, err := cleanUpSuccess(ctx, release)
+		if secondExec != nil {
+			return out, err
+		}
+
+		// The previous ASG is still draining
+		assert.IsType(t, &DrainError{}, err)
+
+		// A second release cannot start until the drain is done
+		second := models.MockRelease(t)
+		second.ReleaseID = to.Strp("second-release")
+		models.AddReleaseS3Objects(maws, second)
+
+		secondMachine := createTestStateMachine(t, maws)
+		secondExec, _ = secondMachine.Execute(second)
+
+		// The instances terminate
+		maws.ASG.DescribeAutoScalingGroupsPageResp[0].Resp.AutoScalingGroups[0].Instances = nil
+
+		return out, err
+	}
+	assert.NoError(t, stateMachine.SetTaskFnHandlers(tm))
+
+	exec, err := stateMachine.Execute(release)
+	assert.NoError(t, err)
+	assert.Equal(t, true, exec.Output["success"])
+
+	assert.Equal(t, []string{
+		"Validate",
+		"Lock",
+		"ValidateResources",
+		"Deploy",
+		"WaitForDeploy",
+		"WaitForHealthy",
+		"CheckHealthy",
+		"Healthy?",
+		"WaitForDetach",
+		"DetachForSuccess",
+		"WaitDetachForSuccess",
+		"CleanUpSuccess",
+		"CleanUpSuccess",
+		"Success",
+	}, exec.Path())
+
+	assert.Equal(t, []string{
+		"Validate",
+		"Lock",
+		"FailureClean",
+	}, secondExec.Path())
+
+	assert.Equal(t, 1, len(maws.ASG.DeletedAutoScalingGroups))
+}
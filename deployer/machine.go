@@ -172,6 +172,35 @@ func StateMachine() (*machine.StateMachine, error) {
         "Resource": "arn:aws:lambda:{{aws_region}}:{{aws_account}}:function:{{lambda_name}}",
         "Comment": "Promote New Resources & Delete Old Resources",
         "Next": "Success",
+        "Retry": [{
+          "Comment": "Retry on Drain Error, for 75 minutes",
+          "ErrorEquals": ["DrainError"],
+          "MaxAttempts": 150,
+          "IntervalSeconds": 30,
+          "BackoffRate": 1.0
+         },{
+          "Comment": "Keep trying to Clean",
+          "ErrorEquals": ["States.ALL"],
+          "MaxAttempts": 3,
+          "IntervalSeconds": 60,
+          "BackoffRate": 1.0
+        }],
+        "Catch": [{
+          "Comment": "Force the deletion rather than fail",
+          "ErrorEquals": ["DrainError"],
+          "ResultPath": "$.error",
+          "Next": "ForceCleanUpSuccess"
+        },{
+          "ErrorEquals": ["States.ALL"],
+          "ResultPath": "$.error",
+          "Next": "FailureDirty"
+        }]
+      },
+      "ForceCleanUpSuccess": {
+        "Type": "TaskFn",
+        "Resource": "arn:aws:lambda:{{aws_region}}:{{aws_account}}:function:{{lambda_name}}",
+        "Comment": "Promote New Resources & Delete Old Resources that did not drain",
+        "Next": "Success",
         "Retry": [{
           "Comment": "Keep trying to Clean",
           "ErrorEquals": ["States.ALL"],
@@ -267,7 +296,12 @@ func StateMachine() (*machine.StateMachine, error) {
 
 // TaskHandlers returns
 func TaskHandlers() *handler.TaskHandlers {
-	return CreateTaskFunctinons(&aws.ClientsStr{})
+	awsc := &aws.ClientsStr{}
+	tm := CreateTaskFunctinons(awsc)
+
+	// Scheduled tasks are invoked directly, they are not states in the state machine
+	(*tm)["Sweep"] = Sweep(awsc)
+	return tm
 }
 
 // CreateTaskFunctinons returns
@@ -282,6 +316,7 @@ func CreateTaskFunctinons(awsc aws.Clients) *handler.TaskHandlers {
 	// success
 	tm["DetachForSuccess"] = DetachForSuccess(awsc)
 	tm["CleanUpSuccess"] = CleanUpSuccess(awsc)
+	tm["ForceCleanUpSuccess"] = ForceCleanUpSuccess(awsc)
 
 	// Failure
 	tm["DetachForFailure"] = DetachForFailure(awsc)
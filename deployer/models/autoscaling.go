@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/coinbase/step/utils/is"
 	"github.com/coinbase/step/utils/to"
@@ -17,9 +18,33 @@ type AutoScalingConfig struct {
 	Spread                 *float64  `json:"spread,omitempty"`
 	Policies               []*Policy `json:"policies,omitempty"`
 
+	// EC2 | ELB, defaults to ELB if the service has ELBs or TargetGroups
+	HealthCheckType *string `json:"health_check_type,omitempty"`
+
+	// Instance lifecycle of long-lived and spot fleets
+	TerminationPolicies              []*string `json:"termination_policies,omitempty"`
+	CapacityRebalance                *bool     `json:"capacity_rebalance,omitempty"`
+	MaxInstanceLifetime              *int64    `json:"max_instance_lifetime,omitempty"` // seconds, 0 is unlimited
+	NewInstancesProtectedFromScaleIn *bool     `json:"new_instances_protected_from_scale_in,omitempty"`
+
 	Strategy *string `json:"strategy,omitempty"`
 }
 
+// Termination policies AWS supports, a Lambda function ARN is also allowed as a custom policy
+var terminationPolicies = map[string]bool{
+	"Default":                   true,
+	"AllocationStrategy":        true,
+	"OldestLaunchTemplate":      true,
+	"OldestLaunchConfiguration": true,
+	"ClosestToNextInstanceHour": true,
+	"NewestInstance":            true,
+	"OldestInstance":            true,
+}
+
+// MaxInstanceLifetime must be 0 or between a day and a year
+const minInstanceLifetime = 86400
+const maxInstanceLifetime = 31536000
+
 // ValidateAttributes validates attributes
 func (a *AutoScalingConfig) ValidateAttributes() error {
 	if a.Strategy == nil {
@@ -68,6 +93,43 @@ func (a *AutoScalingConfig) ValidateAttributes() error {
 		return fmt.Errorf("Policy Names not Unique")
 	}
 
+	switch to.Strs(a.HealthCheckType) {
+	case "", "EC2", "ELB":
+	default:
+		return fmt.Errorf("Autoscaling HealthCheckType must be 'EC2' or 'ELB'")
+	}
+
+	if err := a.validateTerminationPolicies(); err != nil {
+		return err
+	}
+
+	if lt := a.MaxInstanceLifetime; lt != nil && *lt != 0 && (*lt < minInstanceLifetime || *lt > maxInstanceLifetime) {
+		return fmt.Errorf("Autoscaling MaxInstanceLifetime must be 0 or between %v and %v seconds", minInstanceLifetime, maxInstanceLifetime)
+	}
+
+	return nil
+}
+
+func (a *AutoScalingConfig) validateTerminationPolicies() error {
+	if !is.UniqueStrp(a.TerminationPolicies) {
+		return fmt.Errorf("Autoscaling TerminationPolicies not Unique")
+	}
+
+	for i, tp := range a.TerminationPolicies {
+		if strings.HasPrefix(*tp, "arn:aws:lambda:") {
+			continue
+		}
+
+		if !terminationPolicies[*tp] {
+			return fmt.Errorf("Autoscaling TerminationPolicy %v is not supported", *tp)
+		}
+
+		// AWS stops at Default, so anything after it would never be used
+		if *tp == "Default" && i != len(a.TerminationPolicies)-1 {
+			return fmt.Errorf("Autoscaling TerminationPolicy Default must be last")
+		}
+	}
+
 	return nil
 }
 
@@ -48,3 +48,32 @@ func Test_Autoscaling_HealthCheckGracePeriod(t *testing.T) {
 	asg.SetDefaults(nil, to.Intp(2000))
 	assert.Equal(t, *asg.HealthCheckGracePeriod, int64(100))
 }
+
+func Test_Autoscaling_LifecycleAttributes(t *testing.T) {
+	asg := &AutoScalingConfig{
+		HealthCheckType:     to.Strp("ELB"),
+		TerminationPolicies: []*string{to.Strp("OldestInstance"), to.Strp("arn:aws:lambda:us-east-1:000000000000:function:term"), to.Strp("Default")},
+		MaxInstanceLifetime: to.Int64p(604800),
+	}
+	asg.SetDefaults(nil, nil)
+	assert.NoError(t, asg.ValidateAttributes())
+
+	asg.MaxInstanceLifetime = to.Int64p(0)
+	assert.NoError(t, asg.ValidateAttributes())
+
+	invalid := map[string]*AutoScalingConfig{
+		"HealthCheckType":      &AutoScalingConfig{HealthCheckType: to.Strp("TCP")},
+		"is not supported":     &AutoScalingConfig{TerminationPolicies: []*string{to.Strp("Random")}},
+		"Default must be last": &AutoScalingConfig{TerminationPolicies: []*string{to.Strp("Default"), to.Strp("OldestInstance")}},
+		"not Unique":           &AutoScalingConfig{TerminationPolicies: []*string{to.Strp("OldestInstance"), to.Strp("OldestInstance")}},
+		"MaxInstanceLifetime":  &AutoScalingConfig{MaxInstanceLifetime: to.Int64p(3600)},
+	}
+
+	for msg, asg := range invalid {
+		asg.SetDefaults(nil, nil)
+		err := asg.ValidateAttributes()
+		if assert.Error(t, err, msg) {
+			assert.Contains(t, err.Error(), msg)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/coinbase/step/utils/is"
+	"github.com/coinbase/step/utils/to"
+)
+
+var ephemeralRegex = regexp.MustCompile(`^ephemeral([0-9]|1[0-9]|2[0-3])$`)
+
+// Volume types that accept provisioned IOPS and throughput
+var iopsVolumeTypes = map[string]bool{"gp3": true, "io1": true, "io2": true}
+var throughputVolumeTypes = map[string]bool{"gp3": true}
+
+var volumeTypes = map[string]bool{
+	"standard": true,
+	"gp2":      true,
+	"gp3":      true,
+	"io1":      true,
+	"io2":      true,
+	"st1":      true,
+	"sc1":      true,
+}
+
+// BlockDevice is an EBS volume or an instance store volume attached to every instance of a service
+type BlockDevice struct {
+	DeviceName *string `json:"device_name,omitempty"`
+
+	// Instance store volume, e.g. "ephemeral0"
+	VirtualName *string `json:"virtual_name,omitempty"`
+
+	// EBS volume
+	VolumeSize          *int64  `json:"volume_size,omitempty"`
+	VolumeType          *string `json:"volume_type,omitempty"`
+	Iops                *int64  `json:"iops,omitempty"`
+	Throughput          *int64  `json:"throughput,omitempty"`
+	SnapshotID          *string `json:"snapshot_id,omitempty"`
+	DeleteOnTermination *bool   `json:"delete_on_termination,omitempty"`
+	Encrypted           *bool   `json:"encrypted,omitempty"`
+
+	// KmsKeyID can only be used with a launch template, launch configurations do not support it
+	KmsKeyID *string `json:"kms_key_id,omitempty"`
+}
+
+// IsEphemeral returns true if the device is an instance store volume
+func (bd *BlockDevice) IsEphemeral() bool {
+	return bd.VirtualName != nil
+}
+
+// SetDefaults assigns default values
+func (bd *BlockDevice) SetDefaults() {
+	if bd.IsEphemeral() {
+		return
+	}
+
+	if bd.VolumeType == nil {
+		bd.VolumeType = to.Strp("gp2")
+	}
+
+	if bd.KmsKeyID != nil && bd.Encrypted == nil {
+		bd.Encrypted = to.Boolp(true)
+	}
+}
+
+// ValidateAttributes validates attributes
+func (bd *BlockDevice) ValidateAttributes() error {
+	if is.EmptyStr(bd.DeviceName) {
+		return fmt.Errorf("BlockDevice DeviceName must be defined")
+	}
+
+	if bd.IsEphemeral() {
+		if !ephemeralRegex.MatchString(*bd.VirtualName) {
+			return fmt.Errorf("BlockDevice(%v) VirtualName must be ephemeral0 to ephemeral23", *bd.DeviceName)
+		}
+
+		if bd.VolumeSize != nil || bd.VolumeType != nil || bd.Iops != nil || bd.Throughput != nil ||
+			bd.SnapshotID != nil || bd.DeleteOnTermination != nil || bd.Encrypted != nil || bd.KmsKeyID != nil {
+			return fmt.Errorf("BlockDevice(%v) instance store volumes cannot have EBS attributes", *bd.DeviceName)
+		}
+
+		return nil
+	}
+
+	if bd.VolumeSize == nil && bd.SnapshotID == nil {
+		return fmt.Errorf("BlockDevice(%v) VolumeSize or SnapshotID must be defined", *bd.DeviceName)
+	}
+
+	volumeType := to.Strs(bd.VolumeType)
+	if !volumeTypes[volumeType] {
+		return fmt.Errorf("BlockDevice(%v) VolumeType %v is not supported", *bd.DeviceName, volumeType)
+	}
+
+	if bd.Iops != nil && !iopsVolumeTypes[volumeType] {
+		return fmt.Errorf("BlockDevice(%v) Iops only allowed for gp3, io1 and io2 volumes", *bd.DeviceName)
+	}
+
+	if bd.Iops == nil && (volumeType == "io1" || volumeType == "io2") {
+		return fmt.Errorf("BlockDevice(%v) Iops must be defined for %v volumes", *bd.DeviceName, volumeType)
+	}
+
+	if bd.Throughput != nil && !throughputVolumeTypes[volumeType] {
+		return fmt.Errorf("BlockDevice(%v) Throughput only allowed for gp3 volumes", *bd.DeviceName)
+	}
+
+	if bd.KmsKeyID != nil && (bd.Encrypted == nil || !*bd.Encrypted) {
+		return fmt.Errorf("BlockDevice(%v) KmsKeyID requires Encrypted", *bd.DeviceName)
+	}
+
+	return nil
+}
+
+// ToBlockDeviceMapping returns the launch configuration mapping
+func (bd *BlockDevice) ToBlockDeviceMapping() *autoscaling.BlockDeviceMapping {
+	if bd.IsEphemeral() {
+		return &autoscaling.BlockDeviceMapping{
+			DeviceName:  bd.DeviceName,
+			VirtualName: bd.VirtualName,
+		}
+	}
+
+	return &autoscaling.BlockDeviceMapping{
+		DeviceName: bd.DeviceName,
+		Ebs: &autoscaling.Ebs{
+			VolumeSize:          bd.VolumeSize,
+			VolumeType:          bd.VolumeType,
+			Iops:                bd.Iops,
+			Throughput:          bd.Throughput,
+			SnapshotId:          bd.SnapshotID,
+			DeleteOnTermination: bd.DeleteOnTermination,
+			Encrypted:           bd.Encrypted,
+		},
+	}
+}
+
+// ToLaunchTemplateBlockDeviceMapping returns the launch template mapping, which also sets the KMS key
+func (bd *BlockDevice) ToLaunchTemplateBlockDeviceMapping() *ec2.LaunchTemplateBlockDeviceMappingRequest {
+	if bd.IsEphemeral() {
+		return &ec2.LaunchTemplateBlockDeviceMappingRequest{
+			DeviceName:  bd.DeviceName,
+			VirtualName: bd.VirtualName,
+		}
+	}
+
+	return &ec2.LaunchTemplateBlockDeviceMappingRequest{
+		DeviceName: bd.DeviceName,
+		Ebs: &ec2.LaunchTemplateEbsBlockDeviceRequest{
+			VolumeSize:          bd.VolumeSize,
+			VolumeType:          bd.VolumeType,
+			Iops:                bd.Iops,
+			Throughput:          bd.Throughput,
+			SnapshotId:          bd.SnapshotID,
+			DeleteOnTermination: bd.DeleteOnTermination,
+			Encrypted:           bd.Encrypted,
+			KmsKeyId:            bd.KmsKeyID,
+		},
+	}
+}
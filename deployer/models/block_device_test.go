@@ -0,0 +1,56 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BlockDevice_ValidateAttributes(t *testing.T) {
+	valid := []*BlockDevice{
+		&BlockDevice{DeviceName: to.Strp("/dev/xvda"), VolumeSize: to.Int64p(20)},
+		&BlockDevice{DeviceName: to.Strp("/dev/xvdb"), VolumeSize: to.Int64p(500), VolumeType: to.Strp("gp3"), Iops: to.Int64p(6000), Throughput: to.Int64p(500)},
+		&BlockDevice{DeviceName: to.Strp("/dev/xvdc"), VolumeSize: to.Int64p(500), VolumeType: to.Strp("io2"), Iops: to.Int64p(6000)},
+		&BlockDevice{DeviceName: to.Strp("/dev/xvdd"), VolumeSize: to.Int64p(20), KmsKeyID: to.Strp("arn:aws:kms:us-east-1:000000000000:key/key")},
+		&BlockDevice{DeviceName: to.Strp("/dev/xvde"), SnapshotID: to.Strp("snap-123"), DeleteOnTermination: to.Boolp(false)},
+		&BlockDevice{DeviceName: to.Strp("/dev/sdb"), VirtualName: to.Strp("ephemeral0")},
+	}
+
+	for _, bd := range valid {
+		bd.SetDefaults()
+		assert.NoError(t, bd.ValidateAttributes(), *bd.DeviceName)
+	}
+
+	invalid := map[string]*BlockDevice{
+		"DeviceName":     &BlockDevice{VolumeSize: to.Int64p(20)},
+		"VolumeSize":     &BlockDevice{DeviceName: to.Strp("/dev/xvda")},
+		"VolumeType":     &BlockDevice{DeviceName: to.Strp("/dev/xvda"), VolumeSize: to.Int64p(20), VolumeType: to.Strp("gp9")},
+		"Iops only":      &BlockDevice{DeviceName: to.Strp("/dev/xvda"), VolumeSize: to.Int64p(20), Iops: to.Int64p(3000)},
+		"Iops must":      &BlockDevice{DeviceName: to.Strp("/dev/xvda"), VolumeSize: to.Int64p(20), VolumeType: to.Strp("io1")},
+		"Throughput":     &BlockDevice{DeviceName: to.Strp("/dev/xvda"), VolumeSize: to.Int64p(20), VolumeType: to.Strp("io1"), Iops: to.Int64p(3000), Throughput: to.Int64p(500)},
+		"Encrypted":      &BlockDevice{DeviceName: to.Strp("/dev/xvda"), VolumeSize: to.Int64p(20), KmsKeyID: to.Strp("key"), Encrypted: to.Boolp(false)},
+		"ephemeral0 to":  &BlockDevice{DeviceName: to.Strp("/dev/sdb"), VirtualName: to.Strp("ephemeral24")},
+		"instance store": &BlockDevice{DeviceName: to.Strp("/dev/sdb"), VirtualName: to.Strp("ephemeral0"), VolumeSize: to.Int64p(20)},
+	}
+
+	for msg, bd := range invalid {
+		bd.SetDefaults()
+		err := bd.ValidateAttributes()
+		if assert.Error(t, err, msg) {
+			assert.Contains(t, err.Error(), msg)
+		}
+	}
+}
+
+func Test_BlockDevice_SetDefaults(t *testing.T) {
+	bd := &BlockDevice{DeviceName: to.Strp("/dev/xvda"), VolumeSize: to.Int64p(20), KmsKeyID: to.Strp("key")}
+	bd.SetDefaults()
+	assert.Equal(t, "gp2", *bd.VolumeType)
+	assert.True(t, *bd.Encrypted)
+
+	bd = &BlockDevice{DeviceName: to.Strp("/dev/sdb"), VirtualName: to.Strp("ephemeral0")}
+	bd.SetDefaults()
+	assert.Nil(t, bd.VolumeType)
+	assert.Nil(t, bd.Encrypted)
+}
@@ -0,0 +1,291 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/aws/s3"
+	"github.com/coinbase/step/bifrost"
+	"github.com/coinbase/step/utils/to"
+)
+
+// AWS bills a month as 730 hours
+const hoursPerMonth = 730.0
+
+// PricingTable is the USD prices used to estimate costs without calling the AWS Pricing API,
+// it is uploaded to the Odin bucket and refreshed by whoever runs Odin
+type PricingTable struct {
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+
+	// InstanceTypes are the on-demand prices per hour e.g. {"c5.large": 0.085}
+	InstanceTypes map[string]float64 `json:"instance_types,omitempty"`
+
+	// SpotInstanceTypes are the spot prices per hour, services with a spot_price pay at most their bid
+	SpotInstanceTypes map[string]float64 `json:"spot_instance_types,omitempty"`
+
+	// Volumes are the EBS prices per GB-month by volume type e.g. {"gp2": 0.10}
+	Volumes map[string]float64 `json:"volumes,omitempty"`
+}
+
+// CostPolicy limits how much a release can increase the estimated cost without cost_increase_ack
+type CostPolicy struct {
+	MaxMonthlyIncrease *float64 `json:"max_monthly_increase,omitempty"`
+	MaxIncreasePercent *float64 `json:"max_increase_percent,omitempty"`
+}
+
+// CostEstimate is the estimated cost of a release at its desired capacity
+type CostEstimate struct {
+	Hourly   *float64                        `json:"hourly,omitempty"`
+	Monthly  *float64                        `json:"monthly,omitempty"`
+	Services map[string]*ServiceCostEstimate `json:"services,omitempty"`
+
+	// PreviousMonthly is the estimate for the currently deployed release at its current capacity
+	PreviousMonthly *float64 `json:"previous_monthly,omitempty"`
+
+	// Missing lists what had no price in the pricing table and is not in the estimate
+	Missing []string `json:"missing,omitempty"`
+}
+
+// ServiceCostEstimate is the estimated cost of a single service
+type ServiceCostEstimate struct {
+	Instances      *int64   `json:"instances,omitempty"`
+	InstanceHourly *float64 `json:"instance_hourly,omitempty"`
+	VolumeHourly   *float64 `json:"volume_hourly,omitempty"`
+	Hourly         *float64 `json:"hourly,omitempty"`
+	Monthly        *float64 `json:"monthly,omitempty"`
+}
+
+// PricingTablePath returns the S3 path of the pricing table
+func PricingTablePath() *string {
+	return to.Strp("_odin/pricing")
+}
+
+// FetchPricingTable returns the pricing table, or nil if none is uploaded
+func FetchPricingTable(s3c aws.S3API, bucket *string) (*PricingTable, error) {
+	var pricing PricingTable
+	err := s3.GetStruct(s3c, bucket, PricingTablePath(), &pricing)
+
+	switch err.(type) {
+	case nil:
+		return &pricing, nil
+	case *s3.NotFoundError:
+		return nil, nil
+	default:
+		return nil, err
+	}
+}
+
+// MonthlyIncrease returns the estimated monthly increase over the previous release, 0 if there is none
+func (ce *CostEstimate) MonthlyIncrease() float64 {
+	if ce == nil || ce.Monthly == nil || ce.PreviousMonthly == nil {
+		return 0
+	}
+
+	return *ce.Monthly - *ce.PreviousMonthly
+}
+
+// EstimateCost returns the estimated cost of the release at the desired capacity of each service
+func (release *Release) EstimateCost(pricing *PricingTable) *CostEstimate {
+	if pricing == nil {
+		pricing = &PricingTable{}
+	}
+
+	estimate := &CostEstimate{
+		Services: map[string]*ServiceCostEstimate{},
+		Missing:  []string{},
+	}
+
+	hourly := 0.0
+	for name, service := range release.Services {
+		// Jobs are scaled to zero once they complete
+		if service == nil || service.IsJob() {
+			continue
+		}
+
+		sce, serviceHourly, missing := service.estimateCost(pricing)
+		estimate.Services[name] = sce
+		estimate.Missing = append(estimate.Missing, missing...)
+		hourly += serviceHourly
+	}
+
+	sort.Strings(estimate.Missing)
+
+	estimate.Hourly = round(hourly, 4)
+	estimate.Monthly = round(hourly*hoursPerMonth, 2)
+
+	return estimate
+}
+
+// estimateCost returns the rounded estimate with the unrounded hourly cost to total
+func (service *Service) estimateCost(pricing *PricingTable) (*ServiceCostEstimate, float64, []string) {
+	missing := []string{}
+
+	// Built here since the strategy is only set by SetDefaults
+	instances := NewStrategy(service.Autoscaling, service.PreviousDesiredCapacity).DesiredCapacity()
+
+	instanceHourly, ok := pricing.instanceHourly(to.Strs(service.InstanceType), service.SpotPrice)
+	if !ok {
+		missing = append(missing, fmt.Sprintf("%v instance type %v", *service.ServiceName, to.Strs(service.InstanceType)))
+	}
+
+	volumeHourly := 0.0
+	for _, bd := range service.blockDevices() {
+		if bd == nil || bd.IsEphemeral() {
+			continue
+		}
+
+		volumeType := to.Strs(bd.VolumeType)
+		if volumeType == "" {
+			volumeType = "gp2"
+		}
+
+		price, ok := pricing.Volumes[volumeType]
+		if !ok || bd.VolumeSize == nil {
+			missing = append(missing, fmt.Sprintf("%v volume %v", *service.ServiceName, to.Strs(bd.DeviceName)))
+			continue
+		}
+
+		volumeHourly += float64(*bd.VolumeSize) * price / hoursPerMonth
+	}
+
+	hourly := float64(instances) * (instanceHourly + volumeHourly)
+
+	return &ServiceCostEstimate{
+		Instances:      &instances,
+		InstanceHourly: round(instanceHourly, 4),
+		VolumeHourly:   round(volumeHourly, 4),
+		Hourly:         round(hourly, 4),
+		Monthly:        round(hourly*hoursPerMonth, 2),
+	}, hourly, missing
+}
+
+// instanceHourly returns the price per hour, spot instances pay the lower of the spot price and their bid
+func (pricing *PricingTable) instanceHourly(instanceType string, spotPrice *string) (float64, bool) {
+	if pricing == nil {
+		return 0, false
+	}
+
+	if spotPrice == nil {
+		price, ok := pricing.InstanceTypes[instanceType]
+		return price, ok
+	}
+
+	bid, err := strconv.ParseFloat(*spotPrice, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	if price, ok := pricing.SpotInstanceTypes[instanceType]; ok {
+		return math.Min(price, bid), true
+	}
+
+	return bid, true
+}
+
+// ValidateCost estimates the cost of the release and the currently deployed release,
+// it errors if the increase is more than the cost policy allows, either estimate is missing a price,
+// or there is no pricing table, without a cost_increase_ack
+func (release *Release) ValidateCost(s3c aws.S3API, resources *ReleaseResources, pricing *PricingTable, config *OdinConfig) error {
+	if pricing == nil {
+		// Nothing to estimate with, so a cost policy cannot be checked
+		if config == nil || config.CostPolicy == nil || strings.TrimSpace(to.Strs(release.CostIncreaseAck)) != "" {
+			return nil
+		}
+		return fmt.Errorf("Estimated cost increase needs cost_increase_ack: no pricing table at %v", *PricingTablePath())
+	}
+
+	release.CostEstimate = release.EstimateCost(pricing)
+	missing := release.CostEstimate.Missing
+
+	if len(resources.PreviousASGs) > 0 {
+		previous, err := release.previousRelease(s3c, resources)
+		switch err.(type) {
+		case nil:
+			previousEstimate := previous.EstimateCost(pricing)
+			release.CostEstimate.PreviousMonthly = previousEstimate.Monthly
+			for _, m := range previousEstimate.Missing {
+				missing = append(missing, fmt.Sprintf("previous %v", m))
+			}
+		case *s3.NotFoundError:
+			// Previous release was not deployed by Odin, so there is nothing to compare
+		default:
+			return err
+		}
+	}
+
+	if config == nil || config.CostPolicy == nil {
+		return nil
+	}
+
+	reasons := []string{}
+	if release.CostEstimate.PreviousMonthly != nil {
+		reasons = config.CostPolicy.exceeded(release.CostEstimate)
+	}
+
+	// Anything without a price is estimated at $0 so the policy cannot be checked
+	if len(missing) > 0 {
+		reasons = append(reasons, fmt.Sprintf("no price for %v", strings.Join(missing, ", ")))
+	}
+
+	if len(reasons) == 0 || strings.TrimSpace(to.Strs(release.CostIncreaseAck)) != "" {
+		return nil
+	}
+
+	return fmt.Errorf("Estimated cost increase needs cost_increase_ack: %v", strings.Join(reasons, ", "))
+}
+
+func (p *CostPolicy) exceeded(estimate *CostEstimate) []string {
+	reasons := []string{}
+	increase := estimate.MonthlyIncrease()
+
+	if p.MaxMonthlyIncrease != nil && increase > *p.MaxMonthlyIncrease {
+		reasons = append(reasons, fmt.Sprintf("$%.2f/month more than the max $%.2f", increase, *p.MaxMonthlyIncrease))
+	}
+
+	previous := *estimate.PreviousMonthly
+	if p.MaxIncreasePercent != nil && previous > 0 && increase/previous*100 > *p.MaxIncreasePercent {
+		reasons = append(reasons, fmt.Sprintf("%.0f%% more than the max %.0f%%", increase/previous*100, *p.MaxIncreasePercent))
+	}
+
+	return reasons
+}
+
+// previousRelease returns the stored previous release with each service at its current desired capacity
+func (release *Release) previousRelease(s3c aws.S3API, resources *ReleaseResources) (*Release, error) {
+	previous := Release{
+		Release: bifrost.Release{
+			ReleaseID:    resources.PreviousReleaseID,
+			ProjectName:  release.ProjectName,
+			ConfigName:   release.ConfigName,
+			AwsAccountID: release.AwsAccountID,
+			AwsRegion:    release.AwsRegion,
+			Bucket:       release.Bucket,
+		},
+	}
+
+	if err := s3.GetStruct(s3c, previous.Bucket, previous.ReleasePath(), &previous); err != nil {
+		return nil, err
+	}
+
+	previous.Release.SetDefaults(release.AwsRegion, release.AwsAccountID, "coinbase-odin-")
+	previous.SetDefaults()
+
+	for name, service := range previous.Services {
+		if prevASG := resources.PreviousASGs[name]; service != nil && prevASG != nil {
+			service.PreviousDesiredCapacity = prevASG.DesiredCapacity
+		}
+	}
+
+	return &previous, nil
+}
+
+// round returns f rounded to the decimal places
+func round(f float64, places int) *float64 {
+	p := math.Pow(10, float64(places))
+	return to.Float64p(math.Round(f*p) / p)
+}
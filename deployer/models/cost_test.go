@@ -0,0 +1,135 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/coinbase/odin/aws/asg"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockPricingTable() *PricingTable {
+	return &PricingTable{
+		InstanceTypes:     map[string]float64{"t2.small": 0.023},
+		SpotInstanceTypes: map[string]float64{"t2.small": 0.007},
+		Volumes:           map[string]float64{"gp2": 0.10},
+	}
+}
+
+func Test_Release_EstimateCost(t *testing.T) {
+	release := MockRelease(t)
+	MockPrepareRelease(release)
+
+	estimate := release.EstimateCost(mockPricingTable())
+	assert.Equal(t, 0, len(estimate.Missing))
+	assert.Equal(t, int64(1), *estimate.Services["web"].Instances)
+	assert.Equal(t, 0.023, *estimate.Services["web"].InstanceHourly)
+	assert.Equal(t, 0.0164, *estimate.Services["web"].VolumeHourly)
+	assert.Equal(t, 0.0394, *estimate.Hourly)
+	assert.Equal(t, 28.79, *estimate.Monthly)
+
+	// Desired capacity is from the previous ASG
+	release.Services["web"].Autoscaling.MaxSize = to.Int64p(10)
+	release.Services["web"].PreviousDesiredCapacity = to.Int64p(4)
+	assert.Equal(t, 115.16, *release.EstimateCost(mockPricingTable()).Monthly)
+
+	// Spot instances pay the spot price up to their bid
+	release.Services["web"].PreviousDesiredCapacity = nil
+	release.Services["web"].SpotPrice = to.Strp("0.01")
+	assert.Equal(t, 0.007, *release.EstimateCost(mockPricingTable()).Services["web"].InstanceHourly)
+
+	release.Services["web"].SpotPrice = to.Strp("0.005")
+	assert.Equal(t, 0.005, *release.EstimateCost(mockPricingTable()).Services["web"].InstanceHourly)
+
+	// Unknown prices are listed as missing
+	release.Services["web"].SpotPrice = nil
+	release.Services["web"].InstanceType = to.Strp("c5.large")
+	estimate = release.EstimateCost(mockPricingTable())
+	assert.Equal(t, []string{"web instance type c5.large"}, estimate.Missing)
+	assert.Equal(t, 12.0, *estimate.Monthly)
+}
+
+func Test_Release_ValidateCost(t *testing.T) {
+	release := MockRelease(t)
+	release.Services["web"].Autoscaling.MinSize = to.Int64p(10)
+	release.Services["web"].Autoscaling.MaxSize = to.Int64p(10)
+	MockPrepareRelease(release)
+
+	awsc := MockAwsClients(release)
+
+	previousRelease := MockRelease(t)
+	previousRelease.ReleaseID = to.Strp("prevReleaseID")
+	AddReleaseS3Objects(awsc, previousRelease)
+
+	resources := &ReleaseResources{
+		PreviousReleaseID: previousRelease.ReleaseID,
+		PreviousASGs:      map[string]*asg.ASG{"web": &asg.ASG{DesiredCapacity: to.Int64p(1)}},
+	}
+
+	// No pricing table no estimate
+	assert.NoError(t, release.ValidateCost(awsc.S3, resources, nil, &OdinConfig{}))
+	assert.Nil(t, release.CostEstimate)
+
+	// A cost policy without a pricing table cannot be checked
+	config := &OdinConfig{CostPolicy: &CostPolicy{MaxMonthlyIncrease: to.Float64p(1000)}}
+	err := release.ValidateCost(awsc.S3, resources, nil, config)
+	if assert.Error(t, err) {
+		assert.Equal(t, "Estimated cost increase needs cost_increase_ack: no pricing table at _odin/pricing", err.Error())
+	}
+
+	release.CostIncreaseAck = to.Strp("pricing table is not uploaded yet")
+	assert.NoError(t, release.ValidateCost(awsc.S3, resources, nil, config))
+	assert.Nil(t, release.CostEstimate)
+	release.CostIncreaseAck = nil
+
+	assert.NoError(t, release.ValidateCost(awsc.S3, resources, mockPricingTable(), config))
+	assert.Equal(t, 287.9, *release.CostEstimate.Monthly)
+	assert.Equal(t, 28.79, *release.CostEstimate.PreviousMonthly)
+
+	config.CostPolicy = &CostPolicy{MaxMonthlyIncrease: to.Float64p(100), MaxIncreasePercent: to.Float64p(50)}
+	err = release.ValidateCost(awsc.S3, resources, mockPricingTable(), config)
+	if assert.Error(t, err) {
+		assert.Equal(t, "Estimated cost increase needs cost_increase_ack: $259.11/month more than the max $100.00, 900% more than the max 50%", err.Error())
+	}
+
+	release.CostIncreaseAck = to.Strp("Black Friday traffic")
+	assert.NoError(t, release.ValidateCost(awsc.S3, resources, mockPricingTable(), config))
+}
+
+func Test_Release_ValidateCost_Missing(t *testing.T) {
+	release := MockRelease(t)
+	release.Services["web"].InstanceType = to.Strp("c5.large")
+	MockPrepareRelease(release)
+
+	awsc := MockAwsClients(release)
+
+	previousRelease := MockRelease(t)
+	previousRelease.ReleaseID = to.Strp("prevReleaseID")
+	previousRelease.Services["web"].InstanceType = to.Strp("m5.large")
+	AddReleaseS3Objects(awsc, previousRelease)
+
+	resources := &ReleaseResources{
+		PreviousReleaseID: previousRelease.ReleaseID,
+		PreviousASGs:      map[string]*asg.ASG{"web": &asg.ASG{DesiredCapacity: to.Int64p(1)}},
+	}
+
+	// Without a cost policy missing prices are only listed
+	assert.NoError(t, release.ValidateCost(awsc.S3, resources, mockPricingTable(), &OdinConfig{}))
+	assert.Equal(t, []string{"web instance type c5.large"}, release.CostEstimate.Missing)
+
+	// With a cost policy they need an ack since they are estimated at $0
+	config := &OdinConfig{CostPolicy: &CostPolicy{MaxMonthlyIncrease: to.Float64p(1000)}}
+	err := release.ValidateCost(awsc.S3, resources, mockPricingTable(), config)
+	if assert.Error(t, err) {
+		assert.Equal(t, "Estimated cost increase needs cost_increase_ack: no price for web instance type c5.large, previous web instance type m5.large", err.Error())
+	}
+
+	// Also without a previous release to compare
+	err = release.ValidateCost(awsc.S3, &ReleaseResources{}, mockPricingTable(), config)
+	if assert.Error(t, err) {
+		assert.Equal(t, "Estimated cost increase needs cost_increase_ack: no price for web instance type c5.large", err.Error())
+	}
+
+	release.CostIncreaseAck = to.Strp("c5 prices are not in the table yet")
+	assert.NoError(t, release.ValidateCost(awsc.S3, resources, mockPricingTable(), config))
+}
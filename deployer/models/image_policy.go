@@ -0,0 +1,78 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coinbase/odin/aws/ami"
+	"github.com/coinbase/step/utils/to"
+)
+
+// ImagePolicy limits which AMIs can be deployed
+type ImagePolicy struct {
+	// MaxAgeDays is the oldest an AMI can be by its CreationDate
+	MaxAgeDays *int `json:"max_age_days,omitempty"`
+
+	// RejectDeprecated rejects AMIs with a DeprecationTime in the past
+	RejectDeprecated bool `json:"reject_deprecated,omitempty"`
+
+	// RequiredTags must all be on the AMI e.g. {"SecurityScan": "passed"}
+	RequiredTags map[string]string `json:"required_tags,omitempty"`
+}
+
+// Violations returns how the image breaks the policy, empty if it does not
+func (p *ImagePolicy) Violations(im *ami.Image, now time.Time) []string {
+	violations := []string{}
+	if p == nil || im == nil {
+		return violations
+	}
+
+	if p.MaxAgeDays != nil {
+		created, err := time.Parse(time.RFC3339, to.Strs(im.CreationDate))
+		switch {
+		case err != nil:
+			violations = append(violations, "CreationDate unknown")
+		case now.Sub(created) > time.Duration(*p.MaxAgeDays)*24*time.Hour:
+			violations = append(violations, fmt.Sprintf("created %v is older than %v days", *im.CreationDate, *p.MaxAgeDays))
+		}
+	}
+
+	if p.RejectDeprecated && im.DeprecationTime != nil {
+		deprecated, err := time.Parse(time.RFC3339, *im.DeprecationTime)
+		if err != nil || !deprecated.After(now) {
+			violations = append(violations, fmt.Sprintf("deprecated at %v", *im.DeprecationTime))
+		}
+	}
+
+	keys := []string{}
+	for key := range p.RequiredTags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if value, ok := im.Tags[key]; !ok || value != p.RequiredTags[key] {
+			violations = append(violations, fmt.Sprintf("tag %v must be %q", key, p.RequiredTags[key]))
+		}
+	}
+
+	return violations
+}
+
+// ValidateImagePolicy errors if the resolved AMI breaks the policy for this config.
+// With an ami_policy_override the violations are recorded on the release instead
+func (release *Release) ValidateImagePolicy(resources *ReleaseResources, config *OdinConfig, now time.Time) error {
+	violations := config.ImagePolicyFor(release.ConfigName).Violations(resources.Image, now)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	if strings.TrimSpace(to.Strs(release.ImagePolicyOverride)) == "" {
+		return fmt.Errorf("AMI %v violates the AMI policy: %v", to.Strs(resources.Image.ImageID), strings.Join(violations, ", "))
+	}
+
+	release.ImagePolicyViolations = violations
+	return nil
+}
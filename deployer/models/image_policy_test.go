@@ -0,0 +1,83 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coinbase/odin/aws/ami"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ImagePolicy_Violations(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2024-06-01T00:00:00Z")
+	im := &ami.Image{
+		ImageID:      to.Strp("ami-123456"),
+		CreationDate: to.Strp("2024-05-01T00:00:00.000Z"),
+		Tags:         map[string]string{"SecurityScan": "passed"},
+	}
+
+	var nilPolicy *ImagePolicy
+	assert.Equal(t, 0, len(nilPolicy.Violations(im, now)))
+
+	p := &ImagePolicy{
+		MaxAgeDays:       to.Intp(60),
+		RejectDeprecated: true,
+		RequiredTags:     map[string]string{"SecurityScan": "passed"},
+	}
+	assert.Equal(t, 0, len(p.Violations(im, now)))
+
+	p.MaxAgeDays = to.Intp(7)
+	assert.Equal(t, []string{"created 2024-05-01T00:00:00.000Z is older than 7 days"}, p.Violations(im, now))
+
+	p.MaxAgeDays = nil
+	im.DeprecationTime = to.Strp("2024-07-01T00:00:00.000Z")
+	assert.Equal(t, 0, len(p.Violations(im, now)))
+
+	im.DeprecationTime = to.Strp("2024-05-15T00:00:00.000Z")
+	assert.Equal(t, []string{"deprecated at 2024-05-15T00:00:00.000Z"}, p.Violations(im, now))
+
+	im.DeprecationTime = nil
+	im.Tags["SecurityScan"] = "failed"
+	assert.Equal(t, []string{`tag SecurityScan must be "passed"`}, p.Violations(im, now))
+}
+
+func Test_Release_ValidateImagePolicy(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2024-06-01T00:00:00Z")
+	r := MockRelease(t)
+	MockPrepareRelease(r)
+
+	resources := &ReleaseResources{Image: &ami.Image{
+		ImageID:      to.Strp("ami-123456"),
+		CreationDate: to.Strp("2022-01-01T00:00:00.000Z"),
+	}}
+
+	config := &OdinConfig{
+		ImagePolicy: &ImagePolicy{MaxAgeDays: to.Intp(1000)},
+		ImagePolicies: map[string]*ImagePolicy{
+			"production": &ImagePolicy{MaxAgeDays: to.Intp(30)},
+		},
+	}
+
+	assert.NoError(t, r.ValidateImagePolicy(resources, config, now))
+
+	r.ConfigName = to.Strp("production")
+	err := r.ValidateImagePolicy(resources, config, now)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "AMI ami-123456 violates the AMI policy")
+	}
+
+	r.ImagePolicyOverride = to.Strp("  ")
+	assert.Error(t, r.ValidateImagePolicy(resources, config, now))
+
+	r.ImagePolicyOverride = to.Strp("hotfix rollback to known good AMI")
+	assert.NoError(t, r.ValidateImagePolicy(resources, config, now))
+	assert.Equal(t, []string{"created 2022-01-01T00:00:00.000Z is older than 30 days"}, r.ImagePolicyViolations)
+}
+
+func Test_Release_WipeControlledValues_ImagePolicyViolations(t *testing.T) {
+	r := MockRelease(t)
+	r.ImagePolicyViolations = []string{"fake"}
+	r.WipeControlledValues()
+	assert.Nil(t, r.ImagePolicyViolations)
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/aws/ami"
+	"github.com/coinbase/odin/aws/instancetype"
+	"github.com/coinbase/step/aws/s3"
+	"github.com/coinbase/step/utils/to"
+)
+
+// Instance type metadata rarely changes, so it is cached in S3 for this long
+const instanceTypeCacheTTL = 7 * 24 * time.Hour
+
+// InstanceTypeCachePath returns the S3 path of the cached metadata for an instance type in the release's account and region
+func (release *Release) InstanceTypeCachePath(name string) *string {
+	s := fmt.Sprintf("%v/_instance_types/%v/%v", *release.AwsAccountID, *release.AwsRegion, name)
+	return &s
+}
+
+// fetchInstanceType returns the instance type metadata from the S3 cache,
+// or from EC2 if it is not cached or is too old
+func (release *Release) fetchInstanceType(ec2c aws.EC2API, s3c aws.S3API, name *string) (*instancetype.InstanceType, error) {
+	if name == nil {
+		return nil, fmt.Errorf("InstanceType nil")
+	}
+
+	var cached instancetype.InstanceType
+	err := s3.GetStruct(s3c, release.Bucket, release.InstanceTypeCachePath(*name), &cached)
+
+	switch err.(type) {
+	case nil:
+		if cached.FetchedAt != nil && time.Since(*cached.FetchedAt) < instanceTypeCacheTTL {
+			return &cached, nil
+		}
+	case *s3.NotFoundError:
+		// Not cached yet
+	default:
+		return nil, err
+	}
+
+	it, err := instancetype.Find(ec2c, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s3.PutStruct(s3c, release.Bucket, release.InstanceTypeCachePath(*name), it); err != nil {
+		return nil, err
+	}
+
+	return it, nil
+}
+
+// ValidateInstanceType returns an error if the image cannot run on the instance type
+func ValidateInstanceType(service serviceIface, it *instancetype.InstanceType, im *ami.Image) error {
+	if it == nil {
+		return fmt.Errorf("InstanceType is nil")
+	}
+
+	if im == nil {
+		return fmt.Errorf("Image is nil")
+	}
+
+	if im.Architecture != nil && !it.SupportsArchitecture(*im.Architecture) {
+		return fmt.Errorf("Image %v architecture %v not supported by InstanceType %v which supports %v",
+			to.Strs(im.ImageID), *im.Architecture, to.Strs(it.InstanceType), to.StrSlice(it.Architectures))
+	}
+
+	if im.VirtualizationType != nil && !it.SupportsVirtualizationType(*im.VirtualizationType) {
+		return fmt.Errorf("Image %v virtualization type %v not supported by InstanceType %v which supports %v",
+			to.Strs(im.ImageID), *im.VirtualizationType, to.Strs(it.InstanceType), to.StrSlice(it.VirtualizationTypes))
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coinbase/odin/aws/ami"
+	"github.com/coinbase/odin/aws/instancetype"
+	"github.com/coinbase/step/aws/s3"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Release_fetchInstanceType_Caches(t *testing.T) {
+	r := MockRelease(t)
+	MockPrepareRelease(r)
+
+	awsc := MockAwsClients(r)
+	awsc.EC2.AddInstanceType("c7g.large", "arm64", "default")
+
+	it, err := r.fetchInstanceType(awsc.EC2, awsc.S3, to.Strp("c7g.large"))
+	assert.NoError(t, err)
+	assert.True(t, it.SupportsArchitecture("arm64"))
+	assert.True(t, it.EbsOptimized())
+
+	// Served from S3 once cached
+	delete(awsc.EC2.InstanceTypes, "c7g.large")
+	it, err = r.fetchInstanceType(awsc.EC2, awsc.S3, to.Strp("c7g.large"))
+	assert.NoError(t, err)
+	assert.Equal(t, "c7g.large", *it.InstanceType)
+
+	// Stale cache entries are fetched again
+	stale := &instancetype.InstanceType{InstanceType: to.Strp("c7g.large"), FetchedAt: to.Timep(time.Now().Add(-30 * 24 * time.Hour))}
+	assert.NoError(t, s3.PutStruct(awsc.S3, r.Bucket, r.InstanceTypeCachePath("c7g.large"), stale))
+
+	_, err = r.fetchInstanceType(awsc.EC2, awsc.S3, to.Strp("c7g.large"))
+	assert.Error(t, err)
+}
+
+func Test_ValidateInstanceType(t *testing.T) {
+	it := &instancetype.InstanceType{
+		InstanceType:        to.Strp("c7g.large"),
+		Architectures:       []*string{to.Strp("arm64")},
+		VirtualizationTypes: []*string{to.Strp("hvm")},
+	}
+
+	arm := &ami.Image{ImageID: to.Strp("ami-1"), Architecture: to.Strp("arm64"), VirtualizationType: to.Strp("hvm")}
+	x86 := &ami.Image{ImageID: to.Strp("ami-2"), Architecture: to.Strp("x86_64"), VirtualizationType: to.Strp("hvm")}
+	pv := &ami.Image{ImageID: to.Strp("ami-3"), Architecture: to.Strp("arm64"), VirtualizationType: to.Strp("paravirtual")}
+
+	assert.NoError(t, ValidateInstanceType(nil, it, arm))
+	assert.Error(t, ValidateInstanceType(nil, it, x86))
+	assert.Error(t, ValidateInstanceType(nil, it, pv))
+	assert.Error(t, ValidateInstanceType(nil, nil, arm))
+}
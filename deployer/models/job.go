@@ -0,0 +1,150 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	awss3 "github.com/aws/aws-sdk-go/service/s3"
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/aws/asg"
+	"github.com/coinbase/step/aws/s3"
+	"github.com/coinbase/step/utils/to"
+)
+
+// IsJob returns true if the service runs to completion instead of staying up
+func (service *Service) IsJob() bool {
+	return to.Strs(service.Kind) == "job"
+}
+
+// JobDir returns the S3 directory job instances write their result to
+func (service *Service) JobDir() *string {
+	s := fmt.Sprintf("%v/jobs/%v", *service.release.ReleaseDir(), *service.ServiceName)
+	return &s
+}
+
+// JobResultPath returns the S3 path an instance writes "success" to when it completes, anything else is a failure
+func (service *Service) JobResultPath(instanceID string) *string {
+	s := fmt.Sprintf("%v/%v", *service.JobDir(), instanceID)
+	return &s
+}
+
+func (service *Service) validateKind() error {
+	switch to.Strs(service.Kind) {
+	case "", "service":
+		return nil
+	case "job":
+		// valid
+	default:
+		return fmt.Errorf("Kind must be either 'service' or 'job'")
+	}
+
+	as := service.Autoscaling
+	switch {
+	case len(service.ELBs) > 0 || len(service.TargetGroups) > 0:
+		return fmt.Errorf("Kind job cannot have ELBs or TargetGroups")
+	case len(as.Policies) > 0:
+		return fmt.Errorf("Kind job cannot have Autoscaling Policies")
+	case to.Strs(as.Strategy) != "AllAtOnce":
+		return fmt.Errorf("Kind job requires the AllAtOnce Strategy")
+	case *as.MinSize != *as.MaxSize:
+		return fmt.Errorf("Kind job requires Autoscaling MinSize equal to MaxSize, the number of instances to run")
+	case as.Spread != nil && *as.Spread != 0:
+		return fmt.Errorf("Kind job cannot have an Autoscaling Spread")
+	}
+
+	return nil
+}
+
+// jobResultIDs lists the instances that wrote a result under JobDir,
+// including instances that have since left the ASG
+func (service *Service) jobResultIDs(s3c aws.S3API) ([]string, error) {
+	prefix := fmt.Sprintf("%v/", *service.JobDir())
+	input := &awss3.ListObjectsV2Input{
+		Bucket: service.release.Bucket,
+		Prefix: &prefix,
+	}
+
+	ids := []string{}
+	err := s3c.ListObjectsV2Pages(input, func(page *awss3.ListObjectsV2Output, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			ids = append(ids, strings.TrimPrefix(to.Strs(obj.Key), prefix))
+		}
+		return true
+	})
+
+	sort.Strings(ids)
+	return ids, err
+}
+
+// UpdateJobHealthy checks the result of each job instance,
+// the job is healthy once every instance succeeded and its ASG is scaled to zero
+// Results are counted per launched instance, so an instance that succeeded
+// and was then replaced by the ASG is not run again
+func (service *Service) UpdateJobHealthy(asgc aws.ASGAPI, s3c aws.S3API) error {
+	if service.JobComplete {
+		service.Healthy = true
+		return nil
+	}
+
+	all, group, err := asg.GetInstances(asgc, service.CreatedASG)
+	if err != nil {
+		return err // This might retry
+	}
+
+	ids, err := service.jobResultIDs(s3c)
+	if err != nil {
+		return err // This might retry
+	}
+
+	succeeded := map[string]bool{}
+	for _, id := range ids {
+		result, err := s3.GetStr(s3c, service.release.Bucket, service.JobResultPath(id))
+		switch err.(type) {
+		case nil:
+			// Result written
+		case *s3.NotFoundError:
+			continue // Deleted since listing
+		default:
+			return err // This might retry
+		}
+
+		if strings.TrimSpace(to.Strs(result)) != "success" {
+			err := fmt.Errorf("Job %v failed on instance %v: %v", *service.ServiceName, id, strings.TrimSpace(to.Strs(result)))
+			return &HaltError{err}
+		}
+
+		succeeded[id] = true
+	}
+
+	// Instances that terminate before writing their result failed
+	failed := []string{}
+	for _, id := range all.TerminatingIDs() {
+		if !succeeded[id] {
+			failed = append(failed, id)
+		}
+	}
+
+	if int64(len(failed)) > *service.Autoscaling.MaxTerminations {
+		err := fmt.Errorf("Found terming instances %v, %v", *service.ServiceName, strings.Join(failed, ","))
+		return &HaltError{err}
+	}
+
+	service.setHealthy(group, all)
+	service.HealthReport.WaitingOn = to.Strp(fmt.Sprintf("job %v/%v done", len(succeeded), *service.Autoscaling.MinSize))
+	service.Healthy = false
+
+	if int64(len(succeeded)) < *service.Autoscaling.MinSize {
+		return nil
+	}
+
+	// Terminate the job instances
+	if err := service.SetMinDesiredCapacity(asgc, to.Int64p(0), to.Int64p(0)); err != nil {
+		return fmt.Errorf("Setting Min and Desired Capacity Error for %v: %v", *service.ServiceName, err.Error())
+	}
+
+	service.JobComplete = true
+	service.Healthy = true
+
+	return nil
+}
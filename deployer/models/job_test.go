@@ -0,0 +1,133 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockJobRelease(t *testing.T) *Release {
+	r := MockRelease(t)
+	r.Services["migrator"] = MockRelease(t).Services["web"]
+
+	job := r.Services["migrator"]
+	job.Kind = to.Strp("job")
+	job.ELBs = nil
+	job.TargetGroups = nil
+	job.Autoscaling.Policies = nil
+	job.Autoscaling.Spread = to.Float64p(0)
+
+	MockPrepareRelease(r)
+	return r
+}
+
+func Test_Service_ValidateKind(t *testing.T) {
+	r := mockJobRelease(t)
+	job := r.Services["migrator"]
+	assert.NoError(t, job.ValidateAttributes())
+
+	job.Kind = to.Strp("cron")
+	assert.Error(t, job.ValidateAttributes())
+
+	job.Kind = to.Strp("job")
+	job.Autoscaling.MaxSize = to.Int64p(2)
+	err := job.ValidateAttributes()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "MinSize equal to MaxSize")
+	}
+
+	err = r.Services["web"].ValidateAttributes()
+	assert.NoError(t, err)
+
+	r.Services["web"].Kind = to.Strp("job")
+	err = r.Services["web"].ValidateAttributes()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "cannot have ELBs")
+	}
+}
+
+func Test_Release_Jobs_Waves(t *testing.T) {
+	r := mockJobRelease(t)
+
+	waves, err := r.Waves()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"migrator"}, {"web"}}, waves)
+
+	job := r.Services["migrator"]
+	job.SetUserData(to.Strp("{{JOB_DIR}}"))
+	assert.Equal(t, "000000/project/config/1/jobs/migrator", *job.UserData())
+	assert.Equal(t, "000000/project/config/1/jobs/migrator/i-1", *job.JobResultPath("i-1"))
+}
+
+func Test_Release_UpdateHealthy_Job(t *testing.T) {
+	r := mockJobRelease(t)
+	awsc := MockAwsClients(r)
+
+	assert.NoError(t, r.CreateResources(awsc.ASG, awsc.CW, awsc.EC2))
+	assert.NotNil(t, r.Services["migrator"].CreatedASG)
+	assert.Nil(t, r.Services["web"].CreatedASG)
+
+	awsc.ASG.DescribeAutoScalingGroupsPageResp = nil
+	awsc.ASG.AddASG(mocks.MakeMockASG("odin", *r.ProjectName, *r.ConfigName, "migrator", *r.ReleaseID))
+
+	job := r.Services["migrator"]
+
+	// Still running
+	assert.NoError(t, r.UpdateHealthy(awsc.ASG, awsc.ELB, awsc.ALB, awsc.S3))
+	assert.False(t, job.Healthy)
+	assert.Equal(t, "job 0/1 done", *job.HealthReport.WaitingOn)
+	assert.Equal(t, 0, len(r.ReadyServices()))
+
+	// Failed
+	awsc.S3.AddGetObject(*job.JobResultPath("InstanceId1"), "migration 42 failed", nil)
+	err := r.UpdateHealthy(awsc.ASG, awsc.ELB, awsc.ALB, awsc.S3)
+	assert.IsType(t, &HaltError{}, err)
+	assert.Regexp(t, "Job migrator failed on instance InstanceId1: migration 42 failed", err.Error())
+
+	// Succeeded
+	awsc.S3.AddGetObject(*job.JobResultPath("InstanceId1"), "success\n", nil)
+	assert.NoError(t, r.UpdateHealthy(awsc.ASG, awsc.ELB, awsc.ALB, awsc.S3))
+	assert.True(t, job.Healthy)
+	assert.True(t, job.JobComplete)
+	assert.False(t, *r.Healthy)
+
+	// The job gates the other services
+	assert.Equal(t, []*Service{r.Services["web"]}, r.ReadyServices())
+
+	// A completed job stays at zero instances
+	assert.NoError(t, job.ResetDesiredCapacity(awsc.ASG))
+}
+
+func Test_Release_UpdateHealthy_Job_ReplacedInstance(t *testing.T) {
+	r := mockJobRelease(t)
+	awsc := MockAwsClients(r)
+
+	assert.NoError(t, r.CreateResources(awsc.ASG, awsc.CW, awsc.EC2))
+
+	awsc.ASG.DescribeAutoScalingGroupsPageResp = nil
+	awsc.ASG.AddASG(mocks.MakeMockASG("odin", *r.ProjectName, *r.ConfigName, "migrator", *r.ReleaseID))
+
+	job := r.Services["migrator"]
+
+	// i-replaced succeeded and then left the ASG, InstanceId1 replaced it
+	awsc.S3.AddGetObject(*job.JobResultPath("i-replaced"), "success\n", nil)
+	assert.NoError(t, r.UpdateHealthy(awsc.ASG, awsc.ELB, awsc.ALB, awsc.S3))
+	assert.True(t, job.Healthy)
+	assert.True(t, job.JobComplete)
+	assert.Equal(t, "job 1/1 done", *job.HealthReport.WaitingOn)
+
+	// A failure from a replaced instance still halts
+	r = mockJobRelease(t)
+	awsc = MockAwsClients(r)
+	assert.NoError(t, r.CreateResources(awsc.ASG, awsc.CW, awsc.EC2))
+	awsc.ASG.DescribeAutoScalingGroupsPageResp = nil
+	awsc.ASG.AddASG(mocks.MakeMockASG("odin", *r.ProjectName, *r.ConfigName, "migrator", *r.ReleaseID))
+
+	job = r.Services["migrator"]
+	awsc.S3.AddGetObject(*job.JobResultPath("i-replaced"), "migration 42 failed", nil)
+	err := r.UpdateHealthy(awsc.ASG, awsc.ELB, awsc.ALB, awsc.S3)
+	assert.IsType(t, &HaltError{}, err)
+	assert.Regexp(t, "Job migrator failed on instance i-replaced", err.Error())
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/coinbase/odin/aws/instancetype"
+	"github.com/coinbase/odin/aws/keypair"
+	"github.com/coinbase/step/utils/to"
+)
+
+// LaunchOptions are the less common instance settings of a service
+type LaunchOptions struct {
+	// DetailedMonitoring sends instance metrics to CloudWatch every minute instead of every five
+	DetailedMonitoring *bool `json:"detailed_monitoring,omitempty"`
+
+	// CreditSpecification is the CPU credit mode of burstable instances, standard | unlimited
+	// It can only be used with a launch template, launch configurations do not support it
+	CreditSpecification *string `json:"credit_specification,omitempty"`
+
+	// KeyName is an EC2 key pair tagged DeployWith: odin
+	KeyName *string `json:"key_name,omitempty"`
+
+	// EbsOptimized overrides the default from the instance type
+	EbsOptimized *bool `json:"ebs_optimized,omitempty"`
+}
+
+// ValidateAttributes validates attributes
+func (lo *LaunchOptions) ValidateAttributes() error {
+	switch to.Strs(lo.CreditSpecification) {
+	case "", "standard", "unlimited":
+	default:
+		return fmt.Errorf("LaunchOptions CreditSpecification must be 'standard' or 'unlimited'")
+	}
+
+	if lo.KeyName != nil && *lo.KeyName == "" {
+		return fmt.Errorf("LaunchOptions KeyName must not be empty")
+	}
+
+	return nil
+}
+
+// ValidateInstanceType returns an error if the instance type does not support the options
+func (lo *LaunchOptions) ValidateInstanceType(it *instancetype.InstanceType) error {
+	if it == nil {
+		return fmt.Errorf("InstanceType is nil")
+	}
+
+	// Instance types cached before burstable support was recorded are not checked
+	if lo.CreditSpecification != nil && it.BurstablePerformanceSupported != nil && !*it.BurstablePerformanceSupported {
+		return fmt.Errorf("LaunchOptions CreditSpecification requires a burstable InstanceType, %v is not", to.Strs(it.InstanceType))
+	}
+
+	if lo.EbsOptimized != nil && *lo.EbsOptimized && to.Strs(it.EbsOptimizedSupport) == ec2.EbsOptimizedSupportUnsupported {
+		return fmt.Errorf("LaunchOptions EbsOptimized not supported by InstanceType %v", to.Strs(it.InstanceType))
+	}
+
+	return nil
+}
+
+// ValidateKeyPair returns
+func ValidateKeyPair(service serviceIface, kp *keypair.KeyPair) error {
+	if kp == nil {
+		return fmt.Errorf("KeyPair is nil")
+	}
+
+	if kp.DeployWithTag == nil {
+		return fmt.Errorf("KeyPair %v DeployWith Tag nil", to.Strs(kp.KeyName))
+	}
+
+	if *kp.DeployWithTag != "odin" {
+		return fmt.Errorf("KeyPair %v DeployWith Tag expected: %v actual: %v", to.Strs(kp.KeyName), "odin", *kp.DeployWithTag)
+	}
+
+	return nil
+}
+
+// launchOptionsStr returns the options as JSON to compare releases, nil is the same as no options
+func launchOptionsStr(lo *LaunchOptions) *string {
+	if lo == nil {
+		lo = &LaunchOptions{}
+	}
+
+	b, err := json.Marshal(lo)
+	if err != nil {
+		return nil
+	}
+
+	return to.Strp(string(b))
+}
@@ -7,17 +7,24 @@ import (
 	"github.com/coinbase/odin/aws"
 	"github.com/coinbase/odin/aws/iam"
 	"github.com/coinbase/odin/aws/sns"
+	"github.com/coinbase/odin/aws/sqs"
 	"github.com/coinbase/step/utils/is"
 	"github.com/coinbase/step/utils/to"
 )
 
 // LifeCycleHook struct
+// A hook sends its notifications to either an SNS topic or an SQS queue.
+// A hook with neither only emits EventBridge events, so it needs no role.
 type LifeCycleHook struct {
 	Transistion      *string `json:"transition,omitempty"`
 	SNS              *string `json:"sns,omitempty"`
+	SQS              *string `json:"sqs,omitempty"`
 	Role             *string `json:"role,omitempty"`
 	HeartbeatTimeout *int64  `json:"heartbeat_timeout,omitempty"`
 
+	// DefaultResult is the action taken when the heartbeat times out, CONTINUE or ABANDON
+	DefaultResult *string `json:"default_result,omitempty"`
+
 	RoleARN               *string `json:"role_arn,omitempty"`
 	NotificationTargetARN *string `json:"notification_target_arn,omitempty"`
 	Name                  *string `json:"name,omitempty"`
@@ -28,6 +35,7 @@ func (lc *LifeCycleHook) ToLifecycleHookSpecification() *autoscaling.LifecycleHo
 	return &autoscaling.LifecycleHookSpecification{
 		LifecycleHookName: lc.Name,
 		HeartbeatTimeout:  lc.HeartbeatTimeout,
+		DefaultResult:     lc.DefaultResult,
 
 		LifecycleTransition: lc.Transistion,
 
@@ -37,10 +45,11 @@ func (lc *LifeCycleHook) ToLifecycleHookSpecification() *autoscaling.LifecycleHo
 }
 
 // FetchResources validates resources exist
-func (lc *LifeCycleHook) FetchResources(iamc aws.IAMAPI, snsc aws.SNSAPI) error {
-	err := iam.RoleExists(iamc, lc.Role)
-	if err != nil {
-		return err
+func (lc *LifeCycleHook) FetchResources(iamc aws.IAMAPI, snsc aws.SNSAPI, sqsc aws.SQSAPI) error {
+	if lc.Role != nil {
+		if err := iam.RoleExists(iamc, lc.Role); err != nil {
+			return err
+		}
 	}
 
 	if lc.SNS != nil {
@@ -49,6 +58,12 @@ func (lc *LifeCycleHook) FetchResources(iamc aws.IAMAPI, snsc aws.SNSAPI) error
 		}
 	}
 
+	if lc.SQS != nil {
+		if err := sqs.QueueExists(sqsc, lc.NotificationTargetARN); err != nil {
+			return fmt.Errorf("SQS queue does not exist %v", err.Error())
+		}
+	}
+
 	return nil
 }
 
@@ -63,6 +78,10 @@ func (lc *LifeCycleHook) SetDefaults(region *string, accountID *string, name str
 	if lc.SNS != nil && lc.NotificationTargetARN == nil {
 		lc.NotificationTargetARN = to.Strp(fmt.Sprintf("arn:aws:sns:%v:%v:%v", *region, *accountID, *lc.SNS))
 	}
+
+	if lc.SQS != nil && lc.NotificationTargetARN == nil {
+		lc.NotificationTargetARN = to.Strp(fmt.Sprintf("arn:aws:sqs:%v:%v:%v", *region, *accountID, *lc.SQS))
+	}
 }
 
 // ValidateAttributes validates attributes
@@ -72,17 +91,30 @@ func (lc *LifeCycleHook) ValidateAttributes() error {
 		return err
 	}
 
-	if is.EmptyStr(lc.RoleARN) {
+	if lc.SNS != nil && lc.SQS != nil {
+		return fmt.Errorf("Lifecycle cannot have both SNS and SQS")
+	}
+
+	if lc.NotificationTargetARN != nil && is.EmptyStr(lc.NotificationTargetARN) {
+		return fmt.Errorf("Lifecycle NotificationTargetARN empty")
+	}
+
+	// A role is only needed to publish to a notification target
+	if lc.NotificationTargetARN != nil && is.EmptyStr(lc.RoleARN) {
 		return fmt.Errorf("Lifecycle RoleARN nil")
 	}
 
-	if is.EmptyStr(lc.NotificationTargetARN) {
-		return fmt.Errorf("Lifecycle NotificationTargetARN nil")
+	if lc.NotificationTargetARN == nil && lc.RoleARN != nil {
+		return fmt.Errorf("Lifecycle RoleARN requires SNS or SQS")
 	}
 
 	if *lc.Transistion != "autoscaling:EC2_INSTANCE_LAUNCHING" && *lc.Transistion != "autoscaling:EC2_INSTANCE_TERMINATING" {
 		return fmt.Errorf("Transistion must equal either 'autoscaling:EC2_INSTANCE_LAUNCHING' or 'autoscaling:EC2_INSTANCE_TERMINATING'")
 	}
 
+	if lc.DefaultResult != nil && *lc.DefaultResult != "CONTINUE" && *lc.DefaultResult != "ABANDON" {
+		return fmt.Errorf("Lifecycle DefaultResult must equal either 'CONTINUE' or 'ABANDON'")
+	}
+
 	return nil
 }
@@ -3,6 +3,7 @@ package models
 import (
 	"testing"
 
+	"github.com/coinbase/odin/aws/mocks"
 	"github.com/coinbase/step/utils/to"
 	"github.com/stretchr/testify/assert"
 )
@@ -17,3 +18,62 @@ func Test_Lifecycle_Valid(t *testing.T) {
 	lc.SetDefaults(to.Strp("region"), to.Strp("accountID"), "name")
 	assert.NoError(t, lc.ValidateAttributes())
 }
+
+func Test_Lifecycle_SQS(t *testing.T) {
+	lc := &LifeCycleHook{
+		Transistion:   to.Strp("autoscaling:EC2_INSTANCE_TERMINATING"),
+		Role:          to.Strp("role"),
+		SQS:           to.Strp("queue"),
+		DefaultResult: to.Strp("CONTINUE"),
+	}
+
+	lc.SetDefaults(to.Strp("region"), to.Strp("accountID"), "name")
+	assert.NoError(t, lc.ValidateAttributes())
+	assert.Equal(t, "arn:aws:sqs:region:accountID:queue", *lc.NotificationTargetARN)
+
+	awsc := mocks.MockAWS()
+	awsc.IAM.AddGetRole("role")
+	assert.NoError(t, lc.FetchResources(awsc.IAM, awsc.SNS, awsc.SQS))
+
+	awsc.SQS.MissingQueues = []string{"queue"}
+	assert.Error(t, lc.FetchResources(awsc.IAM, awsc.SNS, awsc.SQS))
+}
+
+func Test_Lifecycle_NoTarget(t *testing.T) {
+	lc := &LifeCycleHook{
+		Transistion: to.Strp("autoscaling:EC2_INSTANCE_LAUNCHING"),
+	}
+
+	lc.SetDefaults(to.Strp("region"), to.Strp("accountID"), "name")
+	assert.NoError(t, lc.ValidateAttributes())
+
+	awsc := mocks.MockAWS()
+	assert.NoError(t, lc.FetchResources(awsc.IAM, awsc.SNS, awsc.SQS))
+
+	lc.Role = to.Strp("role")
+	lc.SetDefaults(to.Strp("region"), to.Strp("accountID"), "name")
+	assert.Error(t, lc.ValidateAttributes())
+}
+
+func Test_Lifecycle_Invalid(t *testing.T) {
+	lc := &LifeCycleHook{
+		Transistion: to.Strp("autoscaling:EC2_INSTANCE_LAUNCHING"),
+		Role:        to.Strp("role"),
+		SNS:         to.Strp("sns"),
+		SQS:         to.Strp("queue"),
+	}
+
+	lc.SetDefaults(to.Strp("region"), to.Strp("accountID"), "name")
+	assert.Error(t, lc.ValidateAttributes())
+
+	lc.SQS = nil
+	lc.DefaultResult = to.Strp("RETRY")
+	assert.Error(t, lc.ValidateAttributes())
+
+	lc.DefaultResult = to.Strp("ABANDON")
+	assert.NoError(t, lc.ValidateAttributes())
+
+	lc.Role = nil
+	lc.RoleARN = nil
+	assert.Error(t, lc.ValidateAttributes())
+}
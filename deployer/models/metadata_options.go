@@ -0,0 +1,148 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/coinbase/step/utils/to"
+)
+
+// MetadataOptions configure the instance metadata service (IMDS) of a service's instances
+// Unset values are the AWS defaults: tokens optional, hop limit 1, endpoint enabled and instance tags disabled
+type MetadataOptions struct {
+	HttpTokens              *string `json:"http_tokens,omitempty"`                 // optional | required
+	HttpPutResponseHopLimit *int64  `json:"http_put_response_hop_limit,omitempty"` // 1 to 64
+	HttpEndpoint            *string `json:"http_endpoint,omitempty"`               // enabled | disabled
+	InstanceMetadataTags    *string `json:"instance_metadata_tags,omitempty"`      // enabled | disabled
+}
+
+// SetDefaults assigns the unset values from defaults
+func (mo *MetadataOptions) SetDefaults(defaults *MetadataOptions) {
+	if defaults == nil {
+		return
+	}
+
+	if mo.HttpTokens == nil {
+		mo.HttpTokens = defaults.HttpTokens
+	}
+
+	if mo.HttpPutResponseHopLimit == nil {
+		mo.HttpPutResponseHopLimit = defaults.HttpPutResponseHopLimit
+	}
+
+	if mo.HttpEndpoint == nil {
+		mo.HttpEndpoint = defaults.HttpEndpoint
+	}
+
+	if mo.InstanceMetadataTags == nil {
+		mo.InstanceMetadataTags = defaults.InstanceMetadataTags
+	}
+}
+
+// ValidateAttributes validates attributes
+func (mo *MetadataOptions) ValidateAttributes() error {
+	switch to.Strs(mo.HttpTokens) {
+	case "", "optional", "required":
+	default:
+		return fmt.Errorf("MetadataOptions HttpTokens must be 'optional' or 'required'")
+	}
+
+	if mo.HttpPutResponseHopLimit != nil && (*mo.HttpPutResponseHopLimit < 1 || *mo.HttpPutResponseHopLimit > 64) {
+		return fmt.Errorf("MetadataOptions HttpPutResponseHopLimit must be between 1 and 64")
+	}
+
+	switch to.Strs(mo.HttpEndpoint) {
+	case "", "enabled", "disabled":
+	default:
+		return fmt.Errorf("MetadataOptions HttpEndpoint must be 'enabled' or 'disabled'")
+	}
+
+	switch to.Strs(mo.InstanceMetadataTags) {
+	case "", "enabled", "disabled":
+	default:
+		return fmt.Errorf("MetadataOptions InstanceMetadataTags must be 'enabled' or 'disabled'")
+	}
+
+	return nil
+}
+
+// Loosened returns a description of each value that is less strict than in strict, nil options are the AWS defaults
+func (mo *MetadataOptions) Loosened(strict *MetadataOptions) []string {
+	if strict == nil {
+		strict = &MetadataOptions{}
+	}
+
+	if mo == nil {
+		mo = &MetadataOptions{}
+	}
+
+	loosened := []string{}
+
+	if mo.httpTokens() == "optional" && strict.httpTokens() == "required" {
+		loosened = append(loosened, "HttpTokens 'optional' is looser than 'required'")
+	}
+
+	if mo.hopLimit() > strict.hopLimit() {
+		loosened = append(loosened, fmt.Sprintf("HttpPutResponseHopLimit %v is looser than %v", mo.hopLimit(), strict.hopLimit()))
+	}
+
+	if mo.httpEndpoint() == "enabled" && strict.httpEndpoint() == "disabled" {
+		loosened = append(loosened, "HttpEndpoint 'enabled' is looser than 'disabled'")
+	}
+
+	if mo.instanceMetadataTags() == "enabled" && strict.instanceMetadataTags() == "disabled" {
+		loosened = append(loosened, "InstanceMetadataTags 'enabled' is looser than 'disabled'")
+	}
+
+	return loosened
+}
+
+// ToInstanceMetadataOptions returns the launch configuration options, which do not support InstanceMetadataTags
+func (mo *MetadataOptions) ToInstanceMetadataOptions() *autoscaling.InstanceMetadataOptions {
+	return &autoscaling.InstanceMetadataOptions{
+		HttpTokens:              mo.HttpTokens,
+		HttpPutResponseHopLimit: mo.HttpPutResponseHopLimit,
+		HttpEndpoint:            mo.HttpEndpoint,
+	}
+}
+
+// ToLaunchTemplateInstanceMetadataOptions returns the launch template options
+func (mo *MetadataOptions) ToLaunchTemplateInstanceMetadataOptions() *ec2.LaunchTemplateInstanceMetadataOptionsRequest {
+	return &ec2.LaunchTemplateInstanceMetadataOptionsRequest{
+		HttpTokens:              mo.HttpTokens,
+		HttpPutResponseHopLimit: mo.HttpPutResponseHopLimit,
+		HttpEndpoint:            mo.HttpEndpoint,
+		InstanceMetadataTags:    mo.InstanceMetadataTags,
+	}
+}
+
+// Values with the AWS defaults for unset options
+
+func (mo *MetadataOptions) httpTokens() string {
+	if mo.HttpTokens == nil {
+		return "optional"
+	}
+	return *mo.HttpTokens
+}
+
+func (mo *MetadataOptions) hopLimit() int64 {
+	if mo.HttpPutResponseHopLimit == nil {
+		return 1
+	}
+	return *mo.HttpPutResponseHopLimit
+}
+
+func (mo *MetadataOptions) httpEndpoint() string {
+	if mo.HttpEndpoint == nil {
+		return "enabled"
+	}
+	return *mo.HttpEndpoint
+}
+
+func (mo *MetadataOptions) instanceMetadataTags() string {
+	if mo.InstanceMetadataTags == nil {
+		return "disabled"
+	}
+	return *mo.InstanceMetadataTags
+}
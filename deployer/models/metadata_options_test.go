@@ -0,0 +1,90 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MetadataOptions_ValidateAttributes(t *testing.T) {
+	assert.NoError(t, (&MetadataOptions{}).ValidateAttributes())
+	assert.NoError(t, (&MetadataOptions{
+		HttpTokens:              to.Strp("required"),
+		HttpPutResponseHopLimit: to.Int64p(2),
+		HttpEndpoint:            to.Strp("enabled"),
+		InstanceMetadataTags:    to.Strp("disabled"),
+	}).ValidateAttributes())
+
+	invalid := map[string]*MetadataOptions{
+		"HttpTokens":              &MetadataOptions{HttpTokens: to.Strp("always")},
+		"HttpPutResponseHopLimit": &MetadataOptions{HttpPutResponseHopLimit: to.Int64p(65)},
+		"HttpEndpoint":            &MetadataOptions{HttpEndpoint: to.Strp("on")},
+		"InstanceMetadataTags":    &MetadataOptions{InstanceMetadataTags: to.Strp("on")},
+	}
+
+	for msg, mo := range invalid {
+		err := mo.ValidateAttributes()
+		if assert.Error(t, err, msg) {
+			assert.Contains(t, err.Error(), msg)
+		}
+	}
+}
+
+func Test_MetadataOptions_Loosened(t *testing.T) {
+	strict := &MetadataOptions{
+		HttpTokens:              to.Strp("required"),
+		HttpPutResponseHopLimit: to.Int64p(1),
+		HttpEndpoint:            to.Strp("enabled"),
+	}
+
+	// Unset values are the AWS defaults
+	assert.Equal(t, 1, len((&MetadataOptions{}).Loosened(strict)))
+	assert.Equal(t, 1, len((*MetadataOptions)(nil).Loosened(strict)))
+	assert.Equal(t, 0, len((&MetadataOptions{}).Loosened(nil)))
+
+	assert.Equal(t, 0, len((&MetadataOptions{HttpTokens: to.Strp("required")}).Loosened(strict)))
+	assert.Equal(t, 0, len((&MetadataOptions{HttpTokens: to.Strp("required"), HttpEndpoint: to.Strp("disabled")}).Loosened(strict)))
+
+	loosened := (&MetadataOptions{HttpTokens: to.Strp("optional"), HttpPutResponseHopLimit: to.Int64p(2)}).Loosened(strict)
+	assert.Equal(t, 2, len(loosened))
+
+	assert.Equal(t, 1, len((&MetadataOptions{InstanceMetadataTags: to.Strp("enabled")}).Loosened(&MetadataOptions{InstanceMetadataTags: to.Strp("disabled")})))
+}
+
+func Test_Release_ApplyOdinConfig(t *testing.T) {
+	release := MockRelease(t)
+	config := &OdinConfig{MetadataOptions: &MetadataOptions{HttpTokens: to.Strp("required")}}
+
+	assert.NoError(t, release.ApplyOdinConfig(&OdinConfig{}))
+	assert.Nil(t, release.Services["web"].MetadataOptions)
+
+	assert.NoError(t, release.ApplyOdinConfig(config))
+	assert.Equal(t, "required", *release.Services["web"].MetadataOptions.HttpTokens)
+
+	release = MockRelease(t)
+	release.Services["web"].MetadataOptions = &MetadataOptions{HttpTokens: to.Strp("optional")}
+
+	err := release.ApplyOdinConfig(config)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "looser than the Odin default")
+	}
+}
+
+func Test_Release_Validate_OdinConfig(t *testing.T) {
+	release := MockRelease(t)
+	release.Services["web"].MetadataOptions = &MetadataOptions{HttpTokens: to.Strp("optional")}
+	awsc := MockAwsClients(release)
+	release.ReleaseSHA256 = to.SHA256Struct(release)
+	MockPrepareRelease(release)
+
+	// No OdinConfig uploaded
+	assert.NoError(t, release.Validate(awsc.S3))
+
+	awsc.S3.AddGetObject(*OdinConfigPath(), `{"metadata_options": {"http_tokens": "required"}}`, nil)
+
+	err := release.Validate(awsc.S3)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "HttpTokens")
+	}
+}
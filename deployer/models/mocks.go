@@ -35,6 +35,7 @@ func MockAwsClients(release *Release) *mocks.MockClients {
 
 		awsc.EC2.AddSecurityGroup("web-sg", *release.ProjectName, *release.ConfigName, "web", nil)
 		awsc.EC2.AddImage("ubuntu", "ami-123456")
+		awsc.EC2.AddInstanceType("t2.small", "x86_64", "unsupported")
 		awsc.EC2.AddSubnet("private-subnet", "subnet-1")
 
 		awsc.ELB.AddELB("web-elb", *release.ProjectName, *release.ConfigName, "web")
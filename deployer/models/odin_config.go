@@ -0,0 +1,75 @@
+package models
+
+import (
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/aws/s3"
+	"github.com/coinbase/step/utils/to"
+)
+
+// OdinConfig is the Odin-wide configuration, uploaded to the Odin bucket by whoever runs Odin
+type OdinConfig struct {
+	// MetadataOptions are the default instance metadata options for every service, which services can only tighten
+	MetadataOptions *MetadataOptions `json:"metadata_options,omitempty"`
+
+	// TrustedImageOwners are the AWS account IDs that can share AMIs to deploy,
+	// their AMIs DeployWith tag is checked in the owner account
+	TrustedImageOwners []*string `json:"trusted_ami_owners,omitempty"`
+
+	// ImagePolicy applies to every release, unless ImagePolicies has one for its config name e.g. "production"
+	ImagePolicy   *ImagePolicy            `json:"ami_policy,omitempty"`
+	ImagePolicies map[string]*ImagePolicy `json:"ami_policies,omitempty"`
+
+	// TagPolicy is checked against the tags of every service
+	TagPolicy *TagPolicy `json:"tag_policy,omitempty"`
+
+	// CostPolicy is the max estimated cost increase of a release without a cost_increase_ack
+	CostPolicy *CostPolicy `json:"cost_policy,omitempty"`
+}
+
+// ImagePolicyFor returns the AMI policy for the config name, nil if there is none
+func (config *OdinConfig) ImagePolicyFor(configName *string) *ImagePolicy {
+	if config == nil {
+		return nil
+	}
+
+	if p, ok := config.ImagePolicies[to.Strs(configName)]; ok {
+		return p
+	}
+
+	return config.ImagePolicy
+}
+
+// TrustsImageOwner returns true if AMIs shared by the account can be deployed
+func (config *OdinConfig) TrustsImageOwner(ownerID *string) bool {
+	if config == nil || ownerID == nil {
+		return false
+	}
+
+	for _, trusted := range config.TrustedImageOwners {
+		if trusted != nil && *trusted == *ownerID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OdinConfigPath returns the S3 path of the Odin-wide configuration
+func OdinConfigPath() *string {
+	return to.Strp("_odin/config")
+}
+
+// FetchOdinConfig returns the Odin-wide configuration, or an empty configuration if none is uploaded
+func FetchOdinConfig(s3c aws.S3API, bucket *string) (*OdinConfig, error) {
+	var config OdinConfig
+	err := s3.GetStruct(s3c, bucket, OdinConfigPath(), &config)
+
+	switch err.(type) {
+	case nil:
+		return &config, nil
+	case *s3.NotFoundError:
+		return &OdinConfig{}, nil
+	default:
+		return nil, err
+	}
+}
@@ -2,8 +2,10 @@ package models
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/aws/ami"
 	"github.com/coinbase/step/aws/s3"
 	"github.com/coinbase/step/bifrost"
 	"github.com/coinbase/step/utils/is"
@@ -18,11 +20,25 @@ type Release struct {
 
 	Subnets []*string `json:"subnets,omitempty"`
 
-	Image *string `json:"ami,omitempty"`
+	// Image is an AMI ID or Name tag, ImageSelector finds the AMI by tags and owners
+	Image         *string       `json:"ami,omitempty"`
+	ImageSelector *ami.Selector `json:"ami_selector,omitempty"`
+
+	// ImageID is the AMI ID the Image or ImageSelector resolved to, it is pinned in the stored release.
+	// If it is set when deploying that AMI is used, as long as the Image or ImageSelector matches it
+	ImageID *string `json:"ami_id,omitempty"`
+
+	// ImagePolicyOverride is the reason to deploy an AMI that violates the AMI policy,
+	// the violations it overrode are recorded in the stored release
+	ImagePolicyOverride   *string  `json:"ami_policy_override,omitempty"`
+	ImagePolicyViolations []string `json:"ami_policy_violations,omitempty"`
 
 	userdata       *string // Not serialized
 	UserDataSHA256 *string `json:"user_data_sha256,omitempty"`
 
+	// Tags are merged into every service's tags, the service's value wins
+	Tags map[string]*string `json:"tags,omitempty"`
+
 	// LifeCycleHooks
 	LifeCycleHooks map[string]*LifeCycleHook `json:"lifecycle,omitempty"`
 
@@ -34,16 +50,52 @@ type Release struct {
 	// AWS Service is Downloaded
 	Services map[string]*Service `json:"services,omitempty"` // Downloaded From S3
 
+	// ServicesToDeploy makes a partial release, only these services get new ASGs and the others keep theirs
+	ServicesToDeploy []*string `json:"services_to_deploy,omitempty"`
+
+	// RetiredServices are removed services whose ASGs this release deletes,
+	// RetiringASGs is set by the deployer to the ASGs it will delete for them
+	RetiredServices []*string `json:"retired_services,omitempty"`
+	RetiringASGs    []*string `json:"retiring_asgs,omitempty"`
+
 	// DetachStrategy can be "Detach"(default) | "SkipDetach" || "SkipDetachCheck"
 	DetachStrategy *string `json:"detach_strategy,omitempty"`
 
 	WaitForDetach *int `json:"wait_for_detach,omitempty"`
+
+	// TeardownStrategy can be "Delete"(default) | "Drain"
+	// Drain scales the previous ASGs to zero and waits for their lifecycle hooks before deleting them
+	TeardownStrategy *string `json:"teardown_strategy,omitempty"`
+
+	// DrainingASGs is set by the deployer to the previous ASGs it drains before deleting them
+	DrainingASGs []*string `json:"draining_asgs,omitempty"`
+
+	// CostEstimate is set by the deployer from the pricing table,
+	// CostIncreaseAck is the reason to deploy an increase larger than the cost policy allows
+	CostEstimate    *CostEstimate `json:"cost_estimate,omitempty"`
+	CostIncreaseAck *string       `json:"cost_increase_ack,omitempty"`
+
+	// FailureReport is written by CleanUpFailure before the new ASGs are torn down
+	FailureReport *FailureReportSummary `json:"failure_report,omitempty"`
 }
 
 //////////
 // Getters
 //////////
 
+// ResolvedImage returns the pinned AMI ID if the release has been deployed, otherwise the requested image
+func (release *Release) ResolvedImage() *string {
+	if release.ImageID != nil {
+		return release.ImageID
+	}
+
+	if release.Image == nil && release.ImageSelector != nil {
+		return to.Strp(release.ImageSelector.String())
+	}
+
+	return release.Image
+}
+
 // UserDataPath returns
 func (release *Release) UserDataPath() *string {
 	s := fmt.Sprintf("%v/userdata", *release.ReleaseDir())
@@ -54,6 +106,26 @@ func (release *Release) UserDataPath() *string {
 // Setters
 //////////
 
+// WipeControlledValues removes values that only the deployer should set
+func (release *Release) WipeControlledValues() {
+	release.Release.WipeControlledValues()
+	release.FailureReport = nil
+	release.CostEstimate = nil
+	release.ImagePolicyViolations = nil
+	release.RetiringASGs = nil
+	release.DrainingASGs = nil
+
+	// A service with a CreatedASG is not created by a later wave
+	for _, service := range release.Services {
+		if service != nil {
+			service.CreatedASG = nil
+			service.CarriedASG = nil
+			service.SuspendedProcesses = nil
+			service.JobComplete = false
+		}
+	}
+}
+
 // SetDefaultsWithUserData sets the default values including userdata fetched from S3
 func (release *Release) SetDefaultsWithUserData(s3c aws.S3API) error {
 	release.SetDefaults()
@@ -107,6 +179,10 @@ func (release *Release) SetDefaults() {
 		release.DetachStrategy = to.Strp("Detach")
 	}
 
+	if release.TeardownStrategy == nil {
+		release.TeardownStrategy = to.Strp("Delete")
+	}
+
 	for name, lc := range release.LifeCycleHooks {
 		if lc != nil {
 			lc.SetDefaults(release.AwsRegion, release.AwsAccountID, name)
@@ -155,14 +231,31 @@ func (release *Release) Validate(s3c aws.S3API) error {
 		return fmt.Errorf("%v %v", release.ErrorPrefix(), "DetachStrategy must be either 'Detach', 'SkipDetach', 'SkipDetachCheck'")
 	}
 
-	if release.Image == nil {
-		return fmt.Errorf("%v %v", release.ErrorPrefix(), "AMI image must be provided")
+	if err := release.validateTeardownStrategy(); err != nil {
+		return fmt.Errorf("%v %v", release.ErrorPrefix(), err.Error())
+	}
+
+	if err := release.validateImage(); err != nil {
+		return fmt.Errorf("%v %v", release.ErrorPrefix(), err.Error())
 	}
 
 	if err := release.ValidateUserDataSHA(s3c); err != nil {
 		return fmt.Errorf("%v %v", release.ErrorPrefix(), err.Error())
 	}
 
+	config, err := FetchOdinConfig(s3c, release.Bucket)
+	if err != nil {
+		return fmt.Errorf("%v %v", release.ErrorPrefix(), err.Error())
+	}
+
+	if err := release.ApplyOdinConfig(config); err != nil {
+		return fmt.Errorf("%v %v", release.ErrorPrefix(), err.Error())
+	}
+
+	if err := release.ValidateTagPolicy(config); err != nil {
+		return fmt.Errorf("%v %v", release.ErrorPrefix(), err.Error())
+	}
+
 	if err := release.ValidateServices(); err != nil {
 		return fmt.Errorf("%v %v", release.ErrorPrefix(), err.Error())
 	}
@@ -170,6 +263,73 @@ func (release *Release) Validate(s3c aws.S3API) error {
 	return nil
 }
 
+func (release *Release) validateImage() error {
+	switch {
+	case release.Image == nil && release.ImageSelector == nil:
+		return fmt.Errorf("AMI image must be provided")
+	case release.Image != nil && release.ImageSelector != nil:
+		return fmt.Errorf("Only one of ami and ami_selector can be provided")
+	case release.ImageSelector != nil:
+		return release.ImageSelector.ValidateAttributes()
+	}
+
+	return nil
+}
+
+func (release *Release) validateTeardownStrategy() error {
+	if release.TeardownStrategy == nil {
+		return fmt.Errorf("TeardownStrategy must be provided")
+	}
+
+	switch *release.TeardownStrategy {
+	case "Delete":
+		return nil
+	case "Drain":
+		// skip
+	default:
+		return fmt.Errorf("TeardownStrategy must be either 'Delete' or 'Drain'")
+	}
+
+	// CleanUpSuccess only waits so long for the instances to terminate, AWS defaults the timeout to 3600
+	for name, lc := range release.LifeCycleHooks {
+		if lc == nil || to.Strs(lc.Transistion) != "autoscaling:EC2_INSTANCE_TERMINATING" {
+			continue
+		}
+
+		if lc.HeartbeatTimeout != nil && *lc.HeartbeatTimeout > maxDrainHeartbeatTimeout {
+			return fmt.Errorf("LifeCycle %v HeartbeatTimeout must be at most %v with the Drain TeardownStrategy", name, maxDrainHeartbeatTimeout)
+		}
+	}
+
+	return nil
+}
+
+// ApplyOdinConfig defaults the services with the Odin-wide configuration,
+// it errors if a service is looser than the configuration allows
+func (release *Release) ApplyOdinConfig(config *OdinConfig) error {
+	if config == nil || config.MetadataOptions == nil {
+		return nil
+	}
+
+	for name, service := range release.Services {
+		if service == nil {
+			continue
+		}
+
+		if service.MetadataOptions == nil {
+			service.MetadataOptions = &MetadataOptions{}
+		}
+
+		service.MetadataOptions.SetDefaults(config.MetadataOptions)
+
+		if loosened := service.MetadataOptions.Loosened(config.MetadataOptions); len(loosened) > 0 {
+			return fmt.Errorf("Service %v MetadataOptions looser than the Odin default: %v", name, strings.Join(loosened, ", "))
+		}
+	}
+
+	return nil
+}
+
 // ValidateUserDataSHA validates the userdata has the correct SHA for the release
 func (release *Release) ValidateUserDataSHA(s3c aws.S3API) error {
 	if is.EmptyStr(release.UserDataSHA256) {
@@ -233,5 +393,17 @@ func (release *Release) ValidateServices() error {
 		}
 	}
 
+	if _, err := release.Waves(); err != nil {
+		return err
+	}
+
+	if err := release.validateServicesToDeploy(); err != nil {
+		return err
+	}
+
+	if err := release.validateRetiredServices(); err != nil {
+		return err
+	}
+
 	return nil
 }
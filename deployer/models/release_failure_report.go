@@ -0,0 +1,211 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/aws/asg"
+	"github.com/coinbase/step/aws/s3"
+	"github.com/coinbase/step/utils/to"
+)
+
+// Max number of scaling activities fetched per ASG
+const maxScalingActivities = 20
+
+// Max number of instances per ASG to fetch console output for
+const maxConsoleOutputSamples = 3
+
+var instanceIDRegex = regexp.MustCompile(`i-[0-9a-f]+`)
+
+// FailureReportSummary is stored on the release to point at the full report
+type FailureReportSummary struct {
+	Path    *string `json:"path,omitempty"`
+	Summary *string `json:"summary,omitempty"`
+}
+
+// FailureReport is the evidence of why a release failed, collected before its ASGs are torn down
+type FailureReport struct {
+	ReleaseID   *string                          `json:"release_id,omitempty"`
+	CollectedAt *time.Time                       `json:"collected_at,omitempty"`
+	Summary     *string                          `json:"summary,omitempty"`
+	Services    map[string]*ServiceFailureReport `json:"services,omitempty"`
+}
+
+// ServiceFailureReport is the evidence collected for a single service
+type ServiceFailureReport struct {
+	AutoScalingGroupName *string            `json:"asg_name,omitempty"`
+	Instances            aws.Instances      `json:"instances,omitempty"`
+	Activities           []*ScalingActivity `json:"activities,omitempty"`
+	ConsoleOutput        map[string]*string `json:"console_output,omitempty"`
+	Errors               []string           `json:"errors,omitempty"`
+}
+
+// ScalingActivity is the useful subset of an ASG scaling activity
+type ScalingActivity struct {
+	StartTime     *time.Time `json:"start_time,omitempty"`
+	StatusCode    *string    `json:"status_code,omitempty"`
+	StatusMessage *string    `json:"status_message,omitempty"`
+	Description   *string    `json:"description,omitempty"`
+	Cause         *string    `json:"cause,omitempty"`
+}
+
+// FailureReportPath returns
+func (release *Release) FailureReportPath() *string {
+	s := fmt.Sprintf("%v/failure_report", *release.ReleaseDir())
+	return &s
+}
+
+// CreateFailureReport collects scaling activities and console output for the ASGs of this release,
+// uploads the report to S3 and records its summary on the release.
+// This must be called before the ASGs are torn down
+func (release *Release) CreateFailureReport(asgc aws.ASGAPI, ec2c aws.EC2API, s3c aws.S3API) error {
+	asgs, err := asg.ForProjectConfigReleaseID(asgc, release.ProjectName, release.ConfigName, release.ReleaseID)
+	if err != nil {
+		return err
+	}
+
+	report := &FailureReport{
+		ReleaseID:   release.ReleaseID,
+		CollectedAt: to.Timep(time.Now()),
+		Services:    map[string]*ServiceFailureReport{},
+	}
+
+	for _, group := range asgs {
+		name := group.ServiceID()
+		if sn := group.ServiceName(); sn != nil {
+			name = sn
+		}
+
+		report.Services[*name] = collectServiceFailureReport(asgc, ec2c, group)
+	}
+
+	report.Summary = report.summarize()
+
+	if err := s3.PutStruct(s3c, release.Bucket, release.FailureReportPath(), report); err != nil {
+		return err
+	}
+
+	release.FailureReport = &FailureReportSummary{
+		Path:    to.Strp(fmt.Sprintf("s3://%v/%v", *release.Bucket, *release.FailureReportPath())),
+		Summary: report.Summary,
+	}
+
+	return nil
+}
+
+// collectServiceFailureReport never errors, any issue is recorded in the report so the rest is kept
+func collectServiceFailureReport(asgc aws.ASGAPI, ec2c aws.EC2API, group *asg.ASG) *ServiceFailureReport {
+	sr := &ServiceFailureReport{
+		AutoScalingGroupName: group.ServiceID(),
+		Instances:            group.Instances(),
+		Activities:           []*ScalingActivity{},
+		ConsoleOutput:        map[string]*string{},
+	}
+
+	activities, err := group.Activities(asgc, maxScalingActivities)
+	if err != nil {
+		sr.Errors = append(sr.Errors, fmt.Sprintf("DescribeScalingActivities: %v", err.Error()))
+	}
+
+	for _, a := range activities {
+		sr.Activities = append(sr.Activities, &ScalingActivity{
+			StartTime:     a.StartTime,
+			StatusCode:    a.StatusCode,
+			StatusMessage: a.StatusMessage,
+			Description:   a.Description,
+			Cause:         a.Cause,
+		})
+	}
+
+	for _, id := range sr.sampleInstanceIDs() {
+		output, err := aws.ConsoleOutput(ec2c, to.Strp(id))
+		if err != nil {
+			sr.Errors = append(sr.Errors, fmt.Sprintf("GetConsoleOutput %v: %v", id, err.Error()))
+			continue
+		}
+		sr.ConsoleOutput[id] = output
+	}
+
+	return sr
+}
+
+// sampleInstanceIDs picks unhealthy and terminating instances first,
+// then instances the activities say were terminated and are no longer in the ASG
+func (sr *ServiceFailureReport) sampleInstanceIDs() []string {
+	unhealthy := sr.Instances.UnhealthyIDs()
+	terming := sr.Instances.TerminatingIDs()
+	sort.Strings(unhealthy)
+	sort.Strings(terming)
+
+	candidates := append(unhealthy, terming...)
+	for _, a := range sr.Activities {
+		if a.Description == nil || !strings.HasPrefix(*a.Description, "Terminating") {
+			continue
+		}
+		candidates = append(candidates, instanceIDRegex.FindAllString(*a.Description, -1)...)
+	}
+
+	ids := []string{}
+	seen := map[string]bool{}
+	for _, id := range candidates {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+
+		if len(ids) >= maxConsoleOutputSamples {
+			break
+		}
+	}
+
+	return ids
+}
+
+// failedActivities returns the activities that did not succeed
+func (sr *ServiceFailureReport) failedActivities() []*ScalingActivity {
+	failed := []*ScalingActivity{}
+	for _, a := range sr.Activities {
+		if a.StatusCode == nil {
+			continue
+		}
+
+		switch *a.StatusCode {
+		case "Failed", "Cancelled":
+			failed = append(failed, a)
+		}
+	}
+	return failed
+}
+
+func (report *FailureReport) summarize() *string {
+	if len(report.Services) == 0 {
+		return to.Strp("No ASGs found for release")
+	}
+
+	names := []string{}
+	for name := range report.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := []string{}
+	for _, name := range names {
+		sr := report.Services[name]
+		healthy, unhealthy, terming := sr.Instances.HealthyUnhealthyTerming()
+		failed := sr.failedActivities()
+
+		line := fmt.Sprintf("%v: %v healthy, %v unhealthy, %v terminating, %v failed activities", name, healthy, unhealthy, terming, len(failed))
+		if len(failed) > 0 && failed[0].StatusMessage != nil {
+			line = fmt.Sprintf("%v (%v)", line, *failed[0].StatusMessage)
+		}
+
+		lines = append(lines, line)
+	}
+
+	return to.Strp(strings.Join(lines, "; "))
+}
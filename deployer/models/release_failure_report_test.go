@@ -0,0 +1,61 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/step/aws/s3"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Release_CreateFailureReport_Works(t *testing.T) {
+	// func (release *Release) CreateFailureReport(asgc aws.ASGAPI, ec2c aws.EC2API, s3c aws.S3API) error {
+	r := MockRelease(t)
+	MockPrepareRelease(r)
+
+	awsc := MockAwsClients(r)
+
+	group := mocks.MakeMockASG("web-asg", *r.ProjectName, *r.ConfigName, "web", *r.ReleaseID)
+	group.Instances = mocks.MakeMockASGInstances(1, 1, 1)
+	awsc.ASG.AddASG(group)
+
+	awsc.ASG.AddScalingActivity("web-asg", "Failed", "Launching a new EC2 instance. Status Reason: no capacity", "InsufficientInstanceCapacity")
+	awsc.ASG.AddScalingActivity("web-asg", "Successful", "Terminating EC2 instance: i-0abc123", "")
+	awsc.EC2.ConsoleOutputs = map[string]string{
+		"InstanceId2": "kernel panic",
+		"i-0abc123":   "userdata failed",
+	}
+
+	assert.NoError(t, r.CreateFailureReport(awsc.ASG, awsc.EC2, awsc.S3))
+
+	assert.NotNil(t, r.FailureReport)
+	assert.Equal(t, "web: 1 healthy, 1 unhealthy, 1 terminating, 1 failed activities (InsufficientInstanceCapacity)", *r.FailureReport.Summary)
+	assert.Contains(t, *r.FailureReport.Path, *r.FailureReportPath())
+
+	report := FailureReport{}
+	assert.NoError(t, s3.GetStruct(awsc.S3, r.Bucket, r.FailureReportPath(), &report))
+
+	web := report.Services["web"]
+	assert.Equal(t, 2, len(web.Activities))
+	assert.Equal(t, 3, len(web.ConsoleOutput))
+	assert.Equal(t, "kernel panic", *web.ConsoleOutput["InstanceId2"])
+	assert.Equal(t, "userdata failed", *web.ConsoleOutput["i-0abc123"])
+}
+
+func Test_Release_CreateFailureReport_NoASGs(t *testing.T) {
+	r := MockRelease(t)
+	MockPrepareRelease(r)
+
+	awsc := MockAwsClients(r)
+
+	assert.NoError(t, r.CreateFailureReport(awsc.ASG, awsc.EC2, awsc.S3))
+	assert.Equal(t, "No ASGs found for release", *r.FailureReport.Summary)
+}
+
+func Test_Release_WipeControlledValues_FailureReport(t *testing.T) {
+	r := MockRelease(t)
+	r.FailureReport = &FailureReportSummary{Summary: to.Strp("fake")}
+	r.WipeControlledValues()
+	assert.Nil(t, r.FailureReport)
+}
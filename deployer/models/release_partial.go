@@ -0,0 +1,138 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/aws/asg"
+	"github.com/coinbase/step/utils/is"
+	"github.com/coinbase/step/utils/to"
+)
+
+// IsPartial returns true if the release only deploys some of its services
+func (release *Release) IsPartial() bool {
+	return len(release.ServicesToDeploy) > 0
+}
+
+// Deploys returns true if the release creates a new ASG for the service,
+// the other services of a partial release keep their existing ASGs
+func (release *Release) Deploys(name string) bool {
+	if !release.IsPartial() {
+		return true
+	}
+
+	for _, s := range release.ServicesToDeploy {
+		if to.Strs(s) == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsCarried returns true if the service keeps its existing ASG in a partial release
+func (service *Service) IsCarried() bool {
+	return service.release != nil && !service.release.Deploys(to.Strs(service.ServiceName))
+}
+
+func (release *Release) validateServicesToDeploy() error {
+	if !is.UniqueStrp(release.ServicesToDeploy) {
+		return fmt.Errorf("ServicesToDeploy must be unique")
+	}
+
+	for _, name := range release.ServicesToDeploy {
+		if _, ok := release.Services[to.Strs(name)]; !ok {
+			return fmt.Errorf("ServicesToDeploy has unknown service %v", to.Strs(name))
+		}
+	}
+
+	return nil
+}
+
+// validateCarriedASGs errors if a carried service has no ASG to keep, jobs do not need one
+func (release *Release) validateCarriedASGs(resources *ReleaseResources) error {
+	for name, service := range release.Services {
+		if service == nil || !service.IsCarried() || service.IsJob() {
+			continue
+		}
+
+		if resources.PreviousASGs[name] == nil {
+			return fmt.Errorf("Service %v has no ASG to keep, add it to services_to_deploy", name)
+		}
+	}
+
+	return nil
+}
+
+// ValidateCarriedServices errors if a carried service is defined differently than in the previous release,
+// its existing ASG is kept so the change would never be applied
+func (release *Release) ValidateCarriedServices(s3c aws.S3API, resources *ReleaseResources, config *OdinConfig) error {
+	if !release.IsPartial() || resources.PreviousReleaseID == nil {
+		return nil
+	}
+
+	previous, err := release.previousRelease(s3c, resources)
+	if err != nil {
+		return fmt.Errorf("Cannot compare the carried services with release %v: %v", *resources.PreviousReleaseID, err)
+	}
+
+	if err := previous.ApplyOdinConfig(config); err != nil {
+		return err
+	}
+
+	changed := []string{}
+	for name, service := range release.Services {
+		if service == nil || !service.IsCarried() {
+			continue
+		}
+
+		if service.definition() != previous.Services[name].definition() {
+			changed = append(changed, name)
+		}
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	sort.Strings(changed)
+	return fmt.Errorf("Services [%v] differ from release %v, add them to services_to_deploy to apply the changes", strings.Join(changed, ", "), *resources.PreviousReleaseID)
+}
+
+// definition returns the service as JSON without the values Odin sets while deploying
+func (service *Service) definition() string {
+	if service == nil {
+		return ""
+	}
+
+	def := *service
+	def.Resources = nil
+	def.JobComplete = false
+	def.CreatedASG = nil
+	def.PreviousDesiredCapacity = nil
+	def.CarriedASG = nil
+	def.SuspendedProcesses = nil
+	def.HealthReport = nil
+	def.Healthy = false
+
+	raw, _ := json.Marshal(&def)
+	return string(raw)
+}
+
+// replacedASGs returns the ASGs of the services this release deploys or retires,
+// the ASGs of services that are in neither are never touched
+func (release *Release) replacedASGs(asgs []*asg.ASG) []*asg.ASG {
+	replaced := []*asg.ASG{}
+	for _, group := range asgs {
+		name := to.Strs(group.ServiceName())
+		_, inRelease := release.Services[name]
+		if (inRelease && release.Deploys(name)) || release.IsRetired(name) {
+			replaced = append(replaced, group)
+		}
+	}
+
+	return replaced
+}
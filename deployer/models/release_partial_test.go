@@ -0,0 +1,126 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/coinbase/odin/aws/asg"
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockPartialRelease(t *testing.T) *Release {
+	r := MockRelease(t)
+	r.Services["worker"] = MockRelease(t).Services["web"]
+	r.ServicesToDeploy = []*string{to.Strp("web")}
+	MockPrepareRelease(r)
+	return r
+}
+
+func Test_Release_ValidateServicesToDeploy(t *testing.T) {
+	r := mockPartialRelease(t)
+	assert.NoError(t, r.validateServicesToDeploy())
+	assert.True(t, r.Deploys("web"))
+	assert.False(t, r.Deploys("worker"))
+	assert.True(t, r.Services["worker"].IsCarried())
+
+	r.ServicesToDeploy = []*string{to.Strp("cron")}
+	err := r.validateServicesToDeploy()
+	if assert.Error(t, err) {
+		assert.Equal(t, "ServicesToDeploy has unknown service cron", err.Error())
+	}
+
+	r.ServicesToDeploy = nil
+	assert.True(t, r.Deploys("worker"))
+	assert.False(t, r.Services["worker"].IsCarried())
+}
+
+func Test_Release_Partial_CarriesASGs(t *testing.T) {
+	r := mockPartialRelease(t)
+
+	webASG := &asg.ASG{AutoScalingGroupName: to.Strp("web-old"), DesiredCapacity: to.Int64p(1)}
+	workerASG := &asg.ASG{AutoScalingGroupName: to.Strp("worker-old"), DesiredCapacity: to.Int64p(3)}
+
+	resources := &ReleaseResources{
+		PreviousASGs: map[string]*asg.ASG{"web": webASG},
+		ServiceResources: map[string]*ServiceResources{
+			"web":    &ServiceResources{PrevASG: webASG},
+			"worker": &ServiceResources{},
+		},
+	}
+
+	err := r.validateCarriedASGs(resources)
+	if assert.Error(t, err) {
+		assert.Equal(t, "Service worker has no ASG to keep, add it to services_to_deploy", err.Error())
+	}
+
+	resources.PreviousASGs["worker"] = workerASG
+	resources.ServiceResources["worker"].PrevASG = workerASG
+	assert.NoError(t, r.validateCarriedASGs(resources))
+
+	r.UpdateWithResources(resources)
+	assert.Nil(t, r.Services["web"].CarriedASG)
+	assert.Equal(t, "worker-old", *r.Services["worker"].CarriedASG)
+
+	// Only web gets a new ASG
+	r = mockPartialRelease(t)
+	awsc := MockAwsClients(r)
+	assert.NoError(t, r.CreateResources(awsc.ASG, awsc.CW, awsc.EC2))
+	assert.NotNil(t, r.Services["web"].CreatedASG)
+	assert.Nil(t, r.Services["worker"].CreatedASG)
+
+	assert.NoError(t, r.UpdateHealthy(awsc.ASG, awsc.ELB, awsc.ALB, awsc.S3))
+	assert.True(t, r.Services["worker"].Healthy)
+	assert.Equal(t, 0, len(r.ReadyServices()))
+}
+
+func Test_Release_Partial_SuccessfulTearDown(t *testing.T) {
+	r := mockPartialRelease(t)
+
+	awsc := mocks.MockAWS()
+	awsc.ASG.AddASG(mocks.MakeMockASG("web-old", *r.ProjectName, *r.ConfigName, "web", "old-release"))
+	awsc.ASG.AddASG(mocks.MakeMockASG("worker-old", *r.ProjectName, *r.ConfigName, "worker", "old-release"))
+
+	assert.NoError(t, r.SuccessfulTearDown(awsc.ASG, awsc.CW, awsc.EC2))
+	assert.Equal(t, []string{"web-old"}, awsc.ASG.DeletedAutoScalingGroups)
+}
+
+func Test_Release_ValidateCarriedServices(t *testing.T) {
+	r := mockPartialRelease(t)
+	awsc := MockAwsClients(r)
+
+	previousRelease := mockPartialRelease(t)
+	previousRelease.ReleaseID = to.Strp("prevReleaseID")
+	AddReleaseS3Objects(awsc, previousRelease)
+
+	resources := &ReleaseResources{
+		PreviousReleaseID: previousRelease.ReleaseID,
+		PreviousASGs: map[string]*asg.ASG{
+			"web":    &asg.ASG{DesiredCapacity: to.Int64p(1)},
+			"worker": &asg.ASG{DesiredCapacity: to.Int64p(3)},
+		},
+	}
+
+	// Deployed services can change
+	r.Services["web"].InstanceType = to.Strp("c5.large")
+	assert.NoError(t, r.ValidateCarriedServices(awsc.S3, resources, &OdinConfig{}))
+
+	// Carried services cannot since their ASG is kept
+	r.Services["worker"].InstanceType = to.Strp("c5.large")
+	err := r.ValidateCarriedServices(awsc.S3, resources, &OdinConfig{})
+	if assert.Error(t, err) {
+		assert.Equal(t, "Services [worker] differ from release prevReleaseID, add them to services_to_deploy to apply the changes", err.Error())
+	}
+
+	r.ServicesToDeploy = append(r.ServicesToDeploy, to.Strp("worker"))
+	assert.NoError(t, r.ValidateCarriedServices(awsc.S3, resources, &OdinConfig{}))
+
+	// A carried service must be in the previous release
+	r = mockPartialRelease(t)
+	r.Services["cron"] = MockRelease(t).Services["web"]
+	MockPrepareRelease(r)
+	err = r.ValidateCarriedServices(awsc.S3, resources, &OdinConfig{})
+	if assert.Error(t, err) {
+		assert.Equal(t, "Services [cron] differ from release prevReleaseID, add them to services_to_deploy to apply the changes", err.Error())
+	}
+}
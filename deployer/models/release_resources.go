@@ -2,13 +2,24 @@ package models
 
 import (
 	"fmt"
+	"sort"
+
 	"github.com/coinbase/odin/aws"
 	"github.com/coinbase/odin/aws/ami"
 	"github.com/coinbase/odin/aws/asg"
+	"github.com/coinbase/odin/aws/instancetype"
 	"github.com/coinbase/odin/aws/subnet"
+	"github.com/coinbase/step/aws/s3"
+	"github.com/coinbase/step/utils/to"
 )
 
+// Max heartbeat timeout of a terminating lifecycle hook with the Drain TeardownStrategy, AWS defaults it to 3600.
+// CleanUpSuccess retries DrainError for a little longer than this, then ForceCleanUpSuccess deletes
+// the ASGs anyway since heartbeats can keep a hook waiting past its timeout
+const maxDrainHeartbeatTimeout = 3600
+
 type ReleaseResources struct {
+	Image             *ami.Image
 	PreviousReleaseID *string
 	PreviousASGs      map[string]*asg.ASG
 	ServiceResources  map[string]*ServiceResources
@@ -20,7 +31,7 @@ type ReleaseResources struct {
 
 // FetchResources checks the existence of all Resources references in this release
 // and returns a struct of the resources
-func (release *Release) FetchResources(asgc aws.ASGAPI, ec2 aws.EC2API, elbc aws.ELBAPI, albc aws.ALBAPI, iamc aws.IAMAPI, snsc aws.SNSAPI) (*ReleaseResources, error) {
+func (release *Release) FetchResources(asgc aws.ASGAPI, ec2 aws.EC2API, elbc aws.ELBAPI, albc aws.ALBAPI, iamc aws.IAMAPI, snsc aws.SNSAPI, sqsc aws.SQSAPI, s3c aws.S3API) (*ReleaseResources, error) {
 	resources := ReleaseResources{
 		ServiceResources: map[string]*ServiceResources{},
 	}
@@ -49,31 +60,52 @@ func (release *Release) FetchResources(asgc aws.ASGAPI, ec2 aws.EC2API, elbc aws
 	}
 
 	// Fetch Image
-	im, err := ami.Find(ec2, release.Image)
+	im, err := release.findImage(ec2)
 	if err != nil {
 		return nil, err
 	}
 
+	resources.Image = im
+
 	// LifeCycleHooks
 	for _, lc := range release.LifeCycleHooks {
-		if err := lc.FetchResources(iamc, snsc); err != nil {
+		if err := lc.FetchResources(iamc, snsc, sqsc); err != nil {
 			return nil, err
 		}
 	}
 
+	// The newest previous ASG is from the last release, older ones can be kept by partial releases
+	var newest *asg.ASG
 	for _, prevASG := range resources.PreviousASGs {
-		// This grabs the first previous ASGs release ID
-		resources.PreviousReleaseID = prevASG.ReleaseID()
-		break
+		if newest == nil || (prevASG.CreatedTime != nil && (newest.CreatedTime == nil || prevASG.CreatedTime.After(*newest.CreatedTime))) {
+			newest = prevASG
+		}
+	}
+
+	if newest != nil {
+		resources.PreviousReleaseID = newest.ReleaseID()
 	}
 
 	slowStartDuration := 0
+	instanceTypes := map[string]*instancetype.InstanceType{}
 	for name, service := range release.Services {
 		sr, err := service.FetchResources(ec2, elbc, albc, iamc)
 		if err != nil {
 			return nil, err
 		}
 
+		// Fetch Instance Type, services often share one
+		if service.InstanceType != nil {
+			it, ok := instanceTypes[*service.InstanceType]
+			if !ok {
+				if it, err = release.fetchInstanceType(ec2, s3c, service.InstanceType); err != nil {
+					return nil, err
+				}
+				instanceTypes[*service.InstanceType] = it
+			}
+			sr.InstanceType = it
+		}
+
 		for _, tg := range sr.TargetGroups {
 			if tg.TargetGroupArn == nil {
 				continue
@@ -107,6 +139,15 @@ func (release *Release) ValidateResources(resources *ReleaseResources) error {
 			return err
 		}
 	}
+
+	if err := release.validateCarriedASGs(resources); err != nil {
+		return fmt.Errorf("%v %v", release.ErrorPrefix(), err.Error())
+	}
+
+	if err := release.validateRemovedServices(resources); err != nil {
+		return fmt.Errorf("%v %v", release.ErrorPrefix(), err.Error())
+	}
+
 	return nil
 }
 
@@ -122,40 +163,142 @@ func (release *Release) UpdateWithResources(resources *ReleaseResources) {
 		}
 		if sr.PrevASG != nil {
 			service.PreviousDesiredCapacity = sr.PrevASG.DesiredCapacity
+
+			if service.IsCarried() {
+				service.CarriedASG = sr.PrevASG.AutoScalingGroupName
+			} else if service.SuspendPreviousScaling {
+				service.SuspendedProcesses = sr.PrevASG.NotSuspended(asg.ScaleInProcesses)
+			}
 		}
 
 		service.Resources = sr.ToServiceResourceNames()
 	}
+
+	release.RetiringASGs = release.retiringASGs(resources)
+	release.DrainingASGs = release.drainingASGs(resources)
+
+	if resources.Image != nil {
+		release.ImageID = resources.Image.ImageID
+	}
 }
 
-//////////
-// Create Resources
-//////////
+// StoreDeployState records what ValidateResources resolved in the stored release: the AMI ID,
+// any overridden AMI policy violations, and the ASGs the release retires, drains or carries.
+// The pinned AMI ID is deployed again if the stored release is, even if the ami_selector now finds a newer one
+func (release *Release) StoreDeployState(s3c aws.S3API) error {
+	var stored Release
+	if err := s3.GetStruct(s3c, release.Bucket, release.ReleasePath(), &stored); err != nil {
+		return err
+	}
 
-// CreateResources returns
-func (release *Release) CreateResources(asgc aws.ASGAPI, cwc aws.CWAPI) error {
-	for _, service := range release.Services {
-		err := service.CreateResources(asgc, cwc)
-		if err != nil {
-			return err
+	stored.ImageID = release.ImageID
+	stored.ImagePolicyViolations = release.ImagePolicyViolations
+	stored.RetiringASGs = release.RetiringASGs
+	stored.DrainingASGs = release.DrainingASGs
+
+	// Record the ASGs a partial release keeps as part of it
+	for name, service := range stored.Services {
+		if s := release.Services[name]; service != nil && s != nil {
+			service.CarriedASG = s.CarriedASG
 		}
 	}
 
+	return s3.PutStruct(s3c, release.Bucket, release.ReleasePath(), &stored)
+}
+
+// FetchSharedImage checks an AMI shared from a trusted owner account with the owner's DeployWith tag,
+// because tags do not propagate to shared AMIs. ownerEC2 returns an EC2 client in the owner account
+func (release *Release) FetchSharedImage(resources *ReleaseResources, config *OdinConfig, ownerEC2 func(accountID *string) aws.EC2API) error {
+	im := resources.Image
+	if im == nil || im.OwnerID == nil || to.Strs(im.OwnerID) == to.Strs(release.AwsAccountID) {
+		return nil
+	}
+
+	if !config.TrustsImageOwner(im.OwnerID) {
+		// The shared AMI has no visible DeployWith tag so will fail ValidateResources
+		return nil
+	}
+
+	ownerIm, err := ami.FindInOwnerAccount(ownerEC2(im.OwnerID), im.ImageID, im.OwnerID)
+	if err != nil {
+		return err
+	}
+
+	// Services share the image so updating it updates them all
+	im.DeployWithTag = ownerIm.DeployWithTag
+	im.Tags = ownerIm.Tags
+
 	return nil
 }
 
+func (release *Release) findImage(ec2 aws.EC2API) (*ami.Image, error) {
+	if release.ImageID != nil {
+		return release.findPinnedImage(ec2)
+	}
+
+	if release.ImageSelector != nil {
+		return ami.FindBySelector(ec2, release.ImageSelector)
+	}
+
+	return ami.Find(ec2, release.Image)
+}
+
+// findPinnedImage returns the image of a pinned ami_id, e.g. when a stored release is deployed again,
+// it errors unless the ami or ami_selector also matches the image
+func (release *Release) findPinnedImage(ec2 aws.EC2API) (*ami.Image, error) {
+	if release.ImageSelector != nil {
+		return ami.FindPinnedBySelector(ec2, release.ImageSelector, release.ImageID)
+	}
+
+	im, err := ami.Find(ec2, release.ImageID)
+	if err != nil || im == nil {
+		return im, err
+	}
+
+	if to.Strs(release.Image) != *release.ImageID && im.Tags["Name"] != to.Strs(release.Image) {
+		return nil, fmt.Errorf("AMI %v does not match ami %v", *release.ImageID, to.Strs(release.Image))
+	}
+
+	return im, nil
+}
+
+//////////
+// Create Resources
+//////////
+
+// CreateResources creates the ASGs of the services that do not depend on another,
+// the later waves are created by CreateReadyResources
+func (release *Release) CreateResources(asgc aws.ASGAPI, cwc aws.CWAPI, ec2c aws.EC2API) error {
+	return release.CreateReadyResources(asgc, cwc, ec2c)
+}
+
 //////////
 // Healthy Resources
 //////////
 
 // UpdateHealthy will try set the Healthy attribute
 // First Error is a Halting Error, Second Error is a Retry Error
-func (release *Release) UpdateHealthy(asgc aws.ASGAPI, elbc aws.ELBAPI, albc aws.ALBAPI) error {
+func (release *Release) UpdateHealthy(asgc aws.ASGAPI, elbc aws.ELBAPI, albc aws.ALBAPI, s3c aws.S3API) error {
 	healthy := true
 
 	for _, service := range release.Services {
+		if service.IsCarried() {
+			// Kept from a previous release
+			service.Healthy = true
+			continue
+		}
+
+		if service.CreatedASG == nil {
+			// Waiting on the services it depends on
+			healthy = false
+			continue
+		}
 
-		if err := service.UpdateHealthy(asgc, elbc, albc); err != nil {
+		if service.IsJob() {
+			if err := service.UpdateJobHealthy(asgc, s3c); err != nil {
+				return err
+			}
+		} else if err := service.UpdateHealthy(asgc, elbc, albc); err != nil {
 			return err
 		}
 
@@ -181,6 +324,11 @@ func (release *Release) IsSkipDetachCheck() bool {
 	return *release.DetachStrategy == "SkipDetachCheck"
 }
 
+// IsDrainTeardown returns true if the previous ASGs are drained before they are deleted
+func (release *Release) IsDrainTeardown() bool {
+	return to.Strs(release.TeardownStrategy) == "Drain"
+}
+
 // Success
 func (release *Release) DetachForSuccess(asgc aws.ASGAPI) error {
 	if release.IsSkipDetachStep() {
@@ -194,6 +342,9 @@ func (release *Release) DetachForSuccess(asgc aws.ASGAPI) error {
 		return err
 	}
 
+	// Only the ASGs of the services this release replaced
+	asgs = release.replacedASGs(asgs)
+
 	// Validate Correct ASG
 	for _, asg := range asgs {
 		if err := release.validSuccessASG(asg); err != nil {
@@ -208,8 +359,18 @@ func (release *Release) DetachForSuccess(asgc aws.ASGAPI) error {
 	return nil
 }
 
-// SuccessfulTearDown returns
-func (release *Release) SuccessfulTearDown(asgc aws.ASGAPI, cwc aws.CWAPI) error {
+// SuccessfulTearDown deletes the previous ASGs. With the Drain TeardownStrategy it scales them to zero
+// and only deletes those without instances, returning a DrainError while any still have some
+func (release *Release) SuccessfulTearDown(asgc aws.ASGAPI, cwc aws.CWAPI, ec2c aws.EC2API) error {
+	return release.successfulTearDown(asgc, cwc, ec2c, release.IsDrainTeardown())
+}
+
+// ForceSuccessfulTearDown deletes the previous ASGs, skipping the terminating hooks of any instances left
+func (release *Release) ForceSuccessfulTearDown(asgc aws.ASGAPI, cwc aws.CWAPI, ec2c aws.EC2API) error {
+	return release.successfulTearDown(asgc, cwc, ec2c, false)
+}
+
+func (release *Release) successfulTearDown(asgc aws.ASGAPI, cwc aws.CWAPI, ec2c aws.EC2API, drain bool) error {
 	// Tear down all resources in NOT in this release
 	asgs, err := asg.ForProjectConfigNOTReleaseID(asgc, release.ProjectName, release.ConfigName, release.ReleaseID)
 
@@ -217,6 +378,9 @@ func (release *Release) SuccessfulTearDown(asgc aws.ASGAPI, cwc aws.CWAPI) error
 		return err
 	}
 
+	// Only the ASGs of the services this release replaced
+	asgs = release.replacedASGs(asgs)
+
 	// Validate Correct ASG
 	for _, asg := range asgs {
 		if err := release.validSuccessASG(asg); err != nil {
@@ -224,13 +388,79 @@ func (release *Release) SuccessfulTearDown(asgc aws.ASGAPI, cwc aws.CWAPI) error
 		}
 	}
 
-	// Delete all Previous Resources
+	// Delete all Previous Resources, when draining only those without instances
+	remaining := []string{}
 	for _, asg := range asgs {
-		if err := asg.Teardown(asgc, cwc); err != nil {
+		if drain {
+			drained, err := asg.Drain(asgc)
+			if err != nil {
+				return err
+			}
+
+			if !drained {
+				remaining = append(remaining, *asg.ServiceID())
+				continue
+			}
+		}
+
+		if err := asg.Teardown(asgc, cwc, ec2c); err != nil {
 			return err
 		}
 	}
 
+	if len(remaining) > 0 {
+		return DrainError{fmt.Sprintf("asgs %s still have instances", remaining)}
+	}
+
+	return nil
+}
+
+// drainingASGs returns the names of the previous ASGs the Drain TeardownStrategy will drain
+func (release *Release) drainingASGs(resources *ReleaseResources) []*string {
+	if !release.IsDrainTeardown() {
+		return nil
+	}
+
+	names := []string{}
+	for name, prevASG := range resources.PreviousASGs {
+		_, inRelease := release.Services[name]
+		if prevASG == nil || prevASG.AutoScalingGroupName == nil {
+			continue
+		}
+
+		if (inRelease && release.Deploys(name)) || release.IsRetired(name) {
+			names = append(names, *prevASG.AutoScalingGroupName)
+		}
+	}
+
+	sort.Strings(names)
+
+	asgs := []*string{}
+	for _, name := range names {
+		asgs = append(asgs, to.Strp(name))
+	}
+
+	return asgs
+}
+
+// ResumePreviousScaling resumes the scaling processes suspended on the previous ASGs,
+// it is best effort after a failure and continues past errors so every service is tried
+func (release *Release) ResumePreviousScaling(asgc aws.ASGAPI) error {
+	errors := []error{}
+	for _, service := range release.Services {
+		if service.IsCarried() {
+			continue
+		}
+
+		if err := service.resumePreviousScaling(asgc); err != nil {
+			errors = append(errors, err)
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("Error ResumePreviousScaling: %v", errors)
+	}
+
 	return nil
 }
 
@@ -293,7 +523,8 @@ func (release *Release) DetachForFailure(asgc aws.ASGAPI) error {
 }
 
 // UnsuccessfulTearDown deletes the services we were trying to create because :(
-func (release *Release) UnsuccessfulTearDown(asgc aws.ASGAPI, cwc aws.CWAPI) error {
+// and lets the previous ASGs scale again
+func (release *Release) UnsuccessfulTearDown(asgc aws.ASGAPI, cwc aws.CWAPI, ec2c aws.EC2API) error {
 	// Tear down all resources in this release
 	asgs, err := asg.ForProjectConfigReleaseID(asgc, release.ProjectName, release.ConfigName, release.ReleaseID)
 	if err != nil {
@@ -308,7 +539,7 @@ func (release *Release) UnsuccessfulTearDown(asgc aws.ASGAPI, cwc aws.CWAPI) err
 
 	// Delete all Resources for this release
 	for _, asg := range asgs {
-		if err := asg.Teardown(asgc, cwc); err != nil {
+		if err := asg.Teardown(asgc, cwc, ec2c); err != nil {
 			return err
 		}
 	}
@@ -325,6 +556,15 @@ func (e DetachError) Error() string {
 	return fmt.Sprintf("DetachError: %v", e.Cause)
 }
 
+// DrainError is returned while the previous ASGs still have instances
+type DrainError struct {
+	Cause string
+}
+
+func (e DrainError) Error() string {
+	return fmt.Sprintf("DrainError: %v", e.Cause)
+}
+
 func (release *Release) validSuccessASG(asg *asg.ASG) error {
 
 	if *release.ProjectName != *asg.ProjectName() {
@@ -1,20 +1,27 @@
 package models
 
 import (
+	"fmt"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/aws/ami"
+	"github.com/coinbase/odin/aws/asg"
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/step/aws/s3"
 	"github.com/coinbase/step/utils/to"
 	"github.com/stretchr/testify/assert"
 )
 
 func Test_Release_FetchResources_Works(t *testing.T) {
-	// func (release *Release) FetchResources(asgc aws.ASGAPI, ec2 aws.EC2API, elbc aws.ELBAPI, albc aws.ALBAPI, iamc aws.IAMAPI, snsc aws.SNSAPI) (map[string]*ServiceResources, error)
+	// func (release *Release) FetchResources(asgc aws.ASGAPI, ec2 aws.EC2API, elbc aws.ELBAPI, albc aws.ALBAPI, iamc aws.IAMAPI, snsc aws.SNSAPI, s3c aws.S3API) (map[string]*ServiceResources, error)
 	r := MockRelease(t)
 	MockPrepareRelease(r)
 
 	awsc := MockAwsClients(r)
 
-	resources, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS)
+	resources, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS, awsc.S3)
 	assert.NoError(t, err)
 
 	assert.Equal(t, 1, len(resources.ServiceResources))
@@ -27,12 +34,239 @@ func Test_Release_ValidateResources_Works(t *testing.T) {
 
 	awsc := MockAwsClients(r)
 
-	sm, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS)
+	sm, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS, awsc.S3)
 	assert.NoError(t, err)
 
 	assert.NoError(t, r.ValidateResources(sm))
 }
 
+func Test_Release_ValidateResources_InstanceType(t *testing.T) {
+	// Unknown instance type
+	r := MockRelease(t)
+	MockPrepareRelease(r)
+	r.Services["web"].InstanceType = to.Strp("x9.huge")
+
+	awsc := MockAwsClients(r)
+
+	_, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS, awsc.S3)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "x9.huge does not exist")
+
+	// x86_64 image on an arm64 type
+	r = MockRelease(t)
+	MockPrepareRelease(r)
+	r.Services["web"].InstanceType = to.Strp("m6g.large")
+
+	awsc = MockAwsClients(r)
+	awsc.EC2.AddInstanceType("m6g.large", "arm64", "default")
+
+	sm, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS, awsc.S3)
+	assert.NoError(t, err)
+
+	err = r.ValidateResources(sm)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "architecture x86_64 not supported")
+}
+
+func Test_Release_ValidateResources_LaunchOptions(t *testing.T) {
+	// Key pair not tagged DeployWith: odin
+	r := MockRelease(t)
+	MockPrepareRelease(r)
+	r.Services["web"].LaunchOptions = &LaunchOptions{KeyName: to.Strp("deploy")}
+
+	awsc := MockAwsClients(r)
+
+	_, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS, awsc.S3)
+	assert.Error(t, err)
+
+	awsc.EC2.AddKeyPair("deploy", "other")
+
+	sm, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS, awsc.S3)
+	assert.NoError(t, err)
+
+	err = r.ValidateResources(sm)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "KeyPair deploy DeployWith Tag")
+	}
+
+	awsc.EC2.AddKeyPair("deploy", "odin")
+
+	sm, err = r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS, awsc.S3)
+	assert.NoError(t, err)
+	assert.NoError(t, r.ValidateResources(sm))
+
+	// Credit specification on a type that is not burstable
+	r = MockRelease(t)
+	MockPrepareRelease(r)
+	r.Services["web"].InstanceType = to.Strp("m6i.large")
+	r.Services["web"].LaunchOptions = &LaunchOptions{CreditSpecification: to.Strp("unlimited")}
+
+	awsc = MockAwsClients(r)
+	awsc.EC2.AddInstanceType("m6i.large", "x86_64", "default")
+
+	sm, err = r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS, awsc.S3)
+	assert.NoError(t, err)
+
+	err = r.ValidateResources(sm)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "requires a burstable InstanceType")
+	}
+
+	// EbsOptimized on a type that does not support it
+	r.Services["web"].InstanceType = to.Strp("t2.small")
+	r.Services["web"].LaunchOptions = &LaunchOptions{EbsOptimized: to.Boolp(true)}
+
+	sm, err = r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS, awsc.S3)
+	assert.NoError(t, err)
+
+	err = r.ValidateResources(sm)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "EbsOptimized not supported")
+	}
+}
+
+func Test_Release_ValidateResources_SecurityGroupID(t *testing.T) {
+	r := MockRelease(t)
+	MockPrepareRelease(r)
+	r.Services["web"].SecurityGroups = []*string{to.Strp("sg-0123456789abcdef0")}
+
+	awsc := MockAwsClients(r)
+	awsc.EC2.AddSecurityGroupWithID("sg-0123456789abcdef0", "other", "config", "web")
+
+	sm, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS, awsc.S3)
+	assert.NoError(t, err)
+
+	err = r.ValidateResources(sm)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "SecurityGroup(sg-0123456789abcdef0) incorrect ProjectName")
+	}
+
+	awsc.EC2.AddSecurityGroupWithID("sg-0123456789abcdef0", *r.ProjectName, *r.ConfigName, "web")
+
+	sm, err = r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS, awsc.S3)
+	assert.NoError(t, err)
+	assert.NoError(t, r.ValidateResources(sm))
+
+	r.UpdateWithResources(sm)
+	assert.Equal(t, "sg-0123456789abcdef0", *r.Services["web"].Resources.SecurityGroups[0])
+}
+
+func Test_Release_ImageSelector_StoreDeployState(t *testing.T) {
+	r := MockRelease(t)
+	r.Image = nil
+	r.ImageSelector = &ami.Selector{Tags: map[string]string{"Name": "ubuntu-base"}, Latest: true}
+	MockPrepareRelease(r)
+
+	awsc := MockAwsClients(r)
+	assert.NoError(t, s3.PutStruct(awsc.S3, r.Bucket, r.ReleasePath(), r))
+
+	awsc.EC2.DescribeImagesResp = nil
+	awsc.EC2.AddImageVersion("ubuntu-base", "ami-000001", "2024-01-01T00:00:00.000Z")
+	awsc.EC2.AddImageVersion("ubuntu-base", "ami-000002", "2024-01-02T00:00:00.000Z")
+
+	sm, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS, awsc.S3)
+	assert.NoError(t, err)
+	assert.NoError(t, r.ValidateResources(sm))
+
+	r.UpdateWithResources(sm)
+	assert.Equal(t, "ami-000002", *r.ImageID)
+	assert.Equal(t, "ami-000002", *r.Services["web"].Resources.Image)
+
+	// The stored release records the pinned ID
+	assert.NoError(t, r.StoreDeployState(awsc.S3))
+
+	var stored Release
+	assert.NoError(t, s3.GetStruct(awsc.S3, r.Bucket, r.ReleasePath(), &stored))
+	assert.Equal(t, "ami-000002", *stored.ImageID)
+	assert.Equal(t, "ami-000002", *stored.ResolvedImage())
+}
+
+func Test_Release_FetchResources_PinnedImage(t *testing.T) {
+	r := MockRelease(t)
+	r.Image = nil
+	r.ImageSelector = &ami.Selector{Tags: map[string]string{"Name": "ubuntu-base"}, Latest: true}
+	r.ImageID = to.Strp("ami-000001")
+	MockPrepareRelease(r)
+
+	awsc := MockAwsClients(r)
+	awsc.EC2.DescribeImagesResp = nil
+	awsc.EC2.AddImageVersion("ubuntu-base", "ami-000001", "2024-01-01T00:00:00.000Z")
+	awsc.EC2.AddImageVersion("ubuntu-base", "ami-000002", "2024-01-02T00:00:00.000Z")
+	awsc.EC2.AddImageVersion("other", "ami-000003", "2024-01-03T00:00:00.000Z")
+
+	// A redeployed release uses its pinned AMI rather than the latest
+	sm, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS, awsc.S3)
+	assert.NoError(t, err)
+	assert.Equal(t, "ami-000001", *sm.Image.ImageID)
+
+	// The pinned AMI must match the selector
+	r.ImageID = to.Strp("ami-000003")
+	_, err = r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS, awsc.S3)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "AMI ami-000003 does not match the selector")
+	}
+
+	// Or the ami
+	r.ImageSelector = nil
+	r.Image = to.Strp("ubuntu-base")
+	_, err = r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS, awsc.S3)
+	if assert.Error(t, err) {
+		assert.Equal(t, "AMI ami-000003 does not match ami ubuntu-base", err.Error())
+	}
+
+	r.ImageID = to.Strp("ami-000002")
+	sm, err = r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS, awsc.S3)
+	assert.NoError(t, err)
+	assert.Equal(t, "ami-000002", *sm.Image.ImageID)
+}
+
+func Test_Release_FetchSharedImage(t *testing.T) {
+	r := MockRelease(t)
+	r.Image = to.Strp("ami-123456") // Tags are not shared, so shared images are found by ID
+	MockPrepareRelease(r)
+
+	awsc := MockAwsClients(r)
+	awsc.EC2.DescribeImagesResp.Resp.Images[0].OwnerId = to.Strp("111111")
+	awsc.EC2.DescribeImagesResp.Resp.Images[0].Tags = nil // Tags are not shared
+
+	ownerEC2 := &mocks.EC2Client{}
+	ownerEC2.AddImage("ubuntu", "ami-123456")
+	ownerFn := func(accountID *string) aws.EC2API {
+		assert.Equal(t, "111111", *accountID)
+		return ownerEC2
+	}
+
+	sm, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS, awsc.S3)
+	assert.NoError(t, err)
+
+	// Owner is not trusted
+	assert.NoError(t, r.FetchSharedImage(sm, &OdinConfig{}, ownerFn))
+	err = r.ValidateResources(sm)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "DeployWith Tag nil")
+	}
+
+	config := &OdinConfig{TrustedImageOwners: []*string{to.Strp("111111")}}
+	assert.NoError(t, r.FetchSharedImage(sm, config, ownerFn))
+	assert.NoError(t, r.ValidateResources(sm))
+}
+
+func Test_Release_UpdateWithResources_EbsOptimized(t *testing.T) {
+	r := MockRelease(t)
+	MockPrepareRelease(r)
+	r.Services["web"].InstanceType = to.Strp("m6i.large")
+
+	awsc := MockAwsClients(r)
+	awsc.EC2.AddInstanceType("m6i.large", "x86_64", "default")
+
+	sm, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS, awsc.S3)
+	assert.NoError(t, err)
+
+	r.UpdateWithResources(sm)
+	assert.True(t, *r.Services["web"].Resources.EbsOptimized)
+	assert.True(t, *r.Services["web"].createLaunchConfigurationInput().EbsOptimized)
+}
+
 func Test_Release_UpdateWithResources_Works(t *testing.T) {
 	// func (release *Release) UpdateWithResources(resources map[string]*ServiceResources) {
 	r := MockRelease(t)
@@ -40,7 +274,7 @@ func Test_Release_UpdateWithResources_Works(t *testing.T) {
 
 	awsc := MockAwsClients(r)
 
-	sm, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS)
+	sm, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS, awsc.S3)
 	assert.NoError(t, err)
 
 	r.UpdateWithResources(sm)
@@ -50,17 +284,103 @@ func Test_Release_FetchResources_Stores_WaitForDetach(t *testing.T) {
 	r := MockRelease(t)
 	MockPrepareRelease(r)
 	awsc := MockAwsClients(r)
-	r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS)
+	r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS, awsc.S3)
 	assert.Equal(t, 42, *r.WaitForDetach)
 }
 
 func Test_Release_CreateResources_Works(t *testing.T) {
-	// func (release *Release) CreateResources(asgc aws.ASGAPI, cwc aws.CWAPI) error {
+	// func (release *Release) CreateResources(asgc aws.ASGAPI, cwc aws.CWAPI, ec2c aws.EC2API) error {
+	r := MockRelease(t)
+	MockPrepareRelease(r)
+
+	awsc := MockAwsClients(r)
+	assert.NoError(t, r.CreateResources(awsc.ASG, awsc.CW, awsc.EC2))
+}
+
+func Test_Release_CreateResources_LaunchTemplate(t *testing.T) {
+	r := MockRelease(t)
+	r.Services["web"].LaunchTemplate = true
+	MockPrepareRelease(r)
+
+	awsc := MockAwsClients(r)
+	assert.NoError(t, r.CreateResources(awsc.ASG, awsc.CW, awsc.EC2))
+	assert.Equal(t, 1, len(awsc.EC2.CreatedLaunchTemplates))
+	assert.Equal(t, *r.Services["web"].ServiceID(), *awsc.EC2.CreatedLaunchTemplates[0].LaunchTemplateName)
+}
+
+func Test_Release_SuspendPreviousScaling(t *testing.T) {
+	r := MockRelease(t)
+	MockPrepareRelease(r)
+	r.Services["web"].SuspendPreviousScaling = true
+
+	awsc := MockAwsClients(r)
+
+	sm, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS, awsc.S3)
+	assert.NoError(t, err)
+	r.UpdateWithResources(sm)
+
+	prevASG := *r.Services["web"].Resources.PrevASG
+
+	assert.NoError(t, r.CreateResources(awsc.ASG, awsc.CW, awsc.EC2))
+	assert.Equal(t, []string{"AlarmNotification", "ScheduledActions", "AZRebalance"}, awsc.ASG.SuspendedProcesses[prevASG])
+	assert.Equal(t, 0, len(awsc.ASG.ResumedProcesses))
+
+	assert.NoError(t, r.UnsuccessfulTearDown(awsc.ASG, awsc.CW, awsc.EC2))
+	assert.Equal(t, 0, len(awsc.ASG.ResumedProcesses))
+
+	assert.NoError(t, r.ResumePreviousScaling(awsc.ASG))
+	assert.Equal(t, []string{"AlarmNotification", "ScheduledActions", "AZRebalance"}, awsc.ASG.ResumedProcesses[prevASG])
+
+	// Resume errors are collected, not returned early
+	awsc.ASG.ResumeProcessesError = fmt.Errorf("ValidationError: AutoScalingGroup name not found")
+	err = r.ResumePreviousScaling(awsc.ASG)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "AutoScalingGroup name not found")
+	}
+
+	// Not suspended without the option
+	r = MockRelease(t)
+	MockPrepareRelease(r)
+
+	awsc = MockAwsClients(r)
+
+	sm, err = r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS, awsc.S3)
+	assert.NoError(t, err)
+	r.UpdateWithResources(sm)
+
+	assert.NoError(t, r.CreateResources(awsc.ASG, awsc.CW, awsc.EC2))
+	assert.Equal(t, 0, len(awsc.ASG.SuspendedProcesses))
+}
+
+func Test_Release_SuspendPreviousScaling_AlreadySuspended(t *testing.T) {
 	r := MockRelease(t)
 	MockPrepareRelease(r)
+	r.Services["web"].SuspendPreviousScaling = true
 
 	awsc := MockAwsClients(r)
-	assert.NoError(t, r.CreateResources(awsc.ASG, awsc.CW))
+
+	// An operator suspended AZRebalance on the previous ASG
+	for _, page := range awsc.ASG.DescribeAutoScalingGroupsPageResp {
+		for _, group := range page.Resp.AutoScalingGroups {
+			group.SuspendedProcesses = []*autoscaling.SuspendedProcess{
+				&autoscaling.SuspendedProcess{ProcessName: to.Strp("AZRebalance")},
+			}
+		}
+	}
+
+	sm, err := r.FetchResources(awsc.ASG, awsc.EC2, awsc.ELB, awsc.ALB, awsc.IAM, awsc.SNS, awsc.SQS, awsc.S3)
+	assert.NoError(t, err)
+	r.UpdateWithResources(sm)
+
+	prevASG := *r.Services["web"].Resources.PrevASG
+	assert.Equal(t, []string{"AlarmNotification", "ScheduledActions"}, to.StrSlice(r.Services["web"].SuspendedProcesses))
+
+	assert.NoError(t, r.CreateResources(awsc.ASG, awsc.CW, awsc.EC2))
+	assert.Equal(t, []string{"AlarmNotification", "ScheduledActions"}, awsc.ASG.SuspendedProcesses[prevASG])
+
+	// AZRebalance stays suspended after a failure
+	assert.NoError(t, r.ResumePreviousScaling(awsc.ASG))
+	assert.Equal(t, []string{"AlarmNotification", "ScheduledActions"}, awsc.ASG.ResumedProcesses[prevASG])
 }
 
 func Test_Release_UpdateHealthy_Works(t *testing.T) {
@@ -70,26 +390,69 @@ func Test_Release_UpdateHealthy_Works(t *testing.T) {
 
 	awsc := MockAwsClients(r)
 
-	assert.NoError(t, r.CreateResources(awsc.ASG, awsc.CW))
-	assert.NoError(t, r.UpdateHealthy(awsc.ASG, awsc.ELB, awsc.ALB))
+	assert.NoError(t, r.CreateResources(awsc.ASG, awsc.CW, awsc.EC2))
+	assert.NoError(t, r.UpdateHealthy(awsc.ASG, awsc.ELB, awsc.ALB, awsc.S3))
 }
 
 func Test_Release_SuccessfulTearDown_Works(t *testing.T) {
-	// func (release *Release) SuccessfulTearDown(asgc aws.ASGAPI, cwc aws.CWAPI) error {
+	// func (release *Release) SuccessfulTearDown(asgc aws.ASGAPI, cwc aws.CWAPI, ec2c aws.EC2API) error {
+	r := MockRelease(t)
+	MockPrepareRelease(r)
+
+	awsc := MockAwsClients(r)
+	assert.NoError(t, r.SuccessfulTearDown(awsc.ASG, awsc.CW, awsc.EC2))
+}
+
+func Test_Release_SuccessfulTearDown_Drain(t *testing.T) {
 	r := MockRelease(t)
+	r.TeardownStrategy = to.Strp("Drain")
 	MockPrepareRelease(r)
 
 	awsc := MockAwsClients(r)
-	assert.NoError(t, r.SuccessfulTearDown(awsc.ASG, awsc.CW))
+
+	// Scales the previous ASGs to zero and waits while instances are terminating
+	err := r.SuccessfulTearDown(awsc.ASG, awsc.CW, awsc.EC2)
+	assert.IsType(t, DrainError{}, err)
+	assert.Equal(t, 0, len(awsc.ASG.DeletedAutoScalingGroups))
+	assert.Equal(t, int64(0), *awsc.ASG.UpdateAutoScalingGroupLastInput.DesiredCapacity)
+
+	// Forced deletes them anyway
+	assert.NoError(t, r.ForceSuccessfulTearDown(awsc.ASG, awsc.CW, awsc.EC2))
+	assert.Equal(t, 1, len(awsc.ASG.DeletedAutoScalingGroups))
+
+	// Deleted once the instances have terminated
+	awsc = mocks.MockAWS()
+	group := mocks.MakeMockASG("old-asg", *r.ProjectName, *r.ConfigName, "web", "old-release")
+	group.Instances = nil
+	awsc.ASG.AddASG(group)
+
+	assert.NoError(t, r.SuccessfulTearDown(awsc.ASG, awsc.CW, awsc.EC2))
+	assert.Equal(t, []string{"old-asg"}, awsc.ASG.DeletedAutoScalingGroups)
+}
+
+func Test_Release_UpdateWithResources_DrainingASGs(t *testing.T) {
+	r := MockRelease(t)
+	MockPrepareRelease(r)
+
+	resources := &ReleaseResources{
+		PreviousASGs: map[string]*asg.ASG{"web": &asg.ASG{AutoScalingGroupName: to.Strp("web-old")}},
+	}
+
+	r.UpdateWithResources(resources)
+	assert.Nil(t, r.DrainingASGs)
+
+	r.TeardownStrategy = to.Strp("Drain")
+	r.UpdateWithResources(resources)
+	assert.Equal(t, []string{"web-old"}, to.StrSlice(r.DrainingASGs))
 }
 
 func Test_Release_UnsuccessfulTearDown_Works(t *testing.T) {
-	// func (release *Release) UnsuccessfulTearDown(asgc aws.ASGAPI, cwc aws.CWAPI) error {
+	// func (release *Release) UnsuccessfulTearDown(asgc aws.ASGAPI, cwc aws.CWAPI, ec2c aws.EC2API) error {
 	r := MockRelease(t)
 	MockPrepareRelease(r)
 
 	awsc := MockAwsClients(r)
-	assert.NoError(t, r.UnsuccessfulTearDown(awsc.ASG, awsc.CW))
+	assert.NoError(t, r.UnsuccessfulTearDown(awsc.ASG, awsc.CW, awsc.EC2))
 }
 
 func Test_Release_ResetDesiredCapacity_Works(t *testing.T) {
@@ -116,3 +479,48 @@ func Test_Release_ResetDesiredCapacity_Works(t *testing.T) {
 	assert.Equal(t, int64(6), *awsc.ASG.UpdateAutoScalingGroupLastInput.DesiredCapacity)
 
 }
+
+func Test_Release_UpdateHealthy_LaunchingHook(t *testing.T) {
+	r := MockRelease(t)
+	r.LifeCycleHooks = map[string]*LifeCycleHook{
+		"warmup": &LifeCycleHook{
+			Transistion: to.Strp("autoscaling:EC2_INSTANCE_LAUNCHING"),
+		},
+	}
+	MockPrepareRelease(r)
+
+	awsc := MockAwsClients(r)
+	assert.NoError(t, r.CreateResources(awsc.ASG, awsc.CW, awsc.EC2))
+
+	// Instance waiting on the hook
+	waitingASG := mocks.MakeMockASG("odin", *r.ProjectName, *r.ConfigName, "web", *r.ReleaseID)
+	waitingASG.Instances[0].LifecycleState = to.Strp("Pending:Wait")
+	awsc.ASG.DescribeAutoScalingGroupsPageResp = nil
+	awsc.ASG.AddASG(waitingASG)
+
+	service := r.Services["web"]
+	assert.NoError(t, service.UpdateHealthy(awsc.ASG, awsc.ELB, awsc.ALB))
+	assert.False(t, service.Healthy)
+	assert.Equal(t, []string{"InstanceId1"}, service.HealthReport.WaitingIDs)
+	assert.Equal(t, "waiting on hook warmup", *service.HealthReport.WaitingOn)
+
+	// Any launching hook could be the one the instance waits on
+	r.LifeCycleHooks["register"] = &LifeCycleHook{
+		Transistion: to.Strp("autoscaling:EC2_INSTANCE_LAUNCHING"),
+	}
+	assert.NoError(t, service.UpdateHealthy(awsc.ASG, awsc.ELB, awsc.ALB))
+	assert.Equal(t, "waiting on one of the hooks register, warmup", *service.HealthReport.WaitingOn)
+	delete(r.LifeCycleHooks, "register")
+
+	// The hook timed out and abandoned the instance
+	waitingASG.Instances[0].LifecycleState = to.Strp("Terminating")
+	err := service.UpdateHealthy(awsc.ASG, awsc.ELB, awsc.ALB)
+	assert.IsType(t, &HaltError{}, err)
+	assert.Regexp(t, "Lifecycle hook warmup abandoned instances", err.Error())
+
+	// A hook that continues does not abandon the instance
+	r.LifeCycleHooks["warmup"].DefaultResult = to.Strp("CONTINUE")
+	err = service.UpdateHealthy(awsc.ASG, awsc.ELB, awsc.ALB)
+	assert.IsType(t, &HaltError{}, err)
+	assert.Regexp(t, "Found terming instances", err.Error())
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/coinbase/step/utils/is"
+	"github.com/coinbase/step/utils/to"
+)
+
+// IsRetired returns true if the service is listed in retired_services
+func (release *Release) IsRetired(name string) bool {
+	for _, s := range release.RetiredServices {
+		if to.Strs(s) == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (release *Release) validateRetiredServices() error {
+	if !is.UniqueStrp(release.RetiredServices) {
+		return fmt.Errorf("RetiredServices must be unique")
+	}
+
+	for _, name := range release.RetiredServices {
+		if is.EmptyStr(name) {
+			return fmt.Errorf("RetiredServices cannot have an empty name")
+		}
+
+		if _, ok := release.Services[*name]; ok {
+			return fmt.Errorf("Service %v cannot be in both services and retired_services", *name)
+		}
+	}
+
+	return nil
+}
+
+// validateRemovedServices errors if a deployed service is missing from the release without being retired,
+// so a mistyped service name cannot delete a fleet
+func (release *Release) validateRemovedServices(resources *ReleaseResources) error {
+	removed := []string{}
+	for name := range resources.PreviousASGs {
+		if _, ok := release.Services[name]; !ok && !release.IsRetired(name) {
+			removed = append(removed, name)
+		}
+	}
+
+	if len(removed) == 0 {
+		return nil
+	}
+
+	sort.Strings(removed)
+	return fmt.Errorf("Services %v are deployed but not in the release, add them to retired_services to delete their ASGs", removed)
+}
+
+// retiringASGs returns the names of the previous ASGs of the retired services
+func (release *Release) retiringASGs(resources *ReleaseResources) []*string {
+	names := []string{}
+	for name, prevASG := range resources.PreviousASGs {
+		if release.IsRetired(name) && prevASG != nil && prevASG.AutoScalingGroupName != nil {
+			names = append(names, *prevASG.AutoScalingGroupName)
+		}
+	}
+
+	sort.Strings(names)
+
+	asgs := []*string{}
+	for _, name := range names {
+		asgs = append(asgs, to.Strp(name))
+	}
+
+	return asgs
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/coinbase/odin/aws/asg"
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Release_ValidateRetiredServices(t *testing.T) {
+	r := MockRelease(t)
+	MockPrepareRelease(r)
+
+	r.RetiredServices = []*string{to.Strp("worker")}
+	assert.NoError(t, r.validateRetiredServices())
+
+	r.RetiredServices = []*string{to.Strp("web")}
+	err := r.validateRetiredServices()
+	if assert.Error(t, err) {
+		assert.Equal(t, "Service web cannot be in both services and retired_services", err.Error())
+	}
+
+	r.RetiredServices = []*string{to.Strp("worker"), to.Strp("worker")}
+	assert.Error(t, r.validateRetiredServices())
+}
+
+func Test_Release_ValidateRemovedServices(t *testing.T) {
+	r := MockRelease(t)
+	MockPrepareRelease(r)
+
+	resources := &ReleaseResources{
+		PreviousASGs: map[string]*asg.ASG{
+			"web":    &asg.ASG{AutoScalingGroupName: to.Strp("web-old")},
+			"worker": &asg.ASG{AutoScalingGroupName: to.Strp("worker-old")},
+		},
+	}
+
+	// A service that silently disappears is refused
+	err := r.validateRemovedServices(resources)
+	if assert.Error(t, err) {
+		assert.Equal(t, "Services [worker] are deployed but not in the release, add them to retired_services to delete their ASGs", err.Error())
+	}
+
+	r.RetiredServices = []*string{to.Strp("worker")}
+	assert.NoError(t, r.validateRemovedServices(resources))
+	assert.Equal(t, []string{"worker-old"}, to.StrSlice(r.retiringASGs(resources)))
+}
+
+func Test_Release_RetiredServices_SuccessfulTearDown(t *testing.T) {
+	r := MockRelease(t)
+	MockPrepareRelease(r)
+	r.RetiredServices = []*string{to.Strp("worker")}
+
+	awsc := mocks.MockAWS()
+	awsc.ASG.AddASG(mocks.MakeMockASG("web-old", *r.ProjectName, *r.ConfigName, "web", "old-release"))
+	awsc.ASG.AddASG(mocks.MakeMockASG("worker-old", *r.ProjectName, *r.ConfigName, "worker", "old-release"))
+	awsc.ASG.AddASG(mocks.MakeMockASG("cron-old", *r.ProjectName, *r.ConfigName, "cron", "old-release"))
+
+	// cron is neither in the release nor retired so its ASG is kept
+	assert.NoError(t, r.SuccessfulTearDown(awsc.ASG, awsc.CW, awsc.EC2))
+	assert.Equal(t, []string{"web-old", "worker-old"}, awsc.ASG.DeletedAutoScalingGroups)
+}
+
+func Test_Release_RetiredServices_SafeRelease(t *testing.T) {
+	r := MockRelease(t)
+	MockPrepareRelease(r)
+
+	prev := MockRelease(t)
+	prev.Services["worker"] = MockRelease(t).Services["web"]
+	MockPrepareRelease(prev)
+
+	sre := r.DiffSafeRelease(prev)
+	if assert.NotNil(t, sre) {
+		assert.NotNil(t, sre.AllServices)
+	}
+
+	r.RetiredServices = []*string{to.Strp("worker")}
+	assert.Nil(t, r.DiffSafeRelease(prev))
+}
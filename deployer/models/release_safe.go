@@ -1,7 +1,10 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/coinbase/step/aws"
 	"github.com/coinbase/step/aws/s3"
@@ -21,6 +24,10 @@ import (
 // 4. Instance Type or Autoscaling Preferences
 // 5. EBS information
 // 6. AssociatePublicIpAddress
+// 7. Looser instance metadata options
+// 8. Launch options
+// 9. LaunchTemplate
+// 10. AMI, if the previous release pinned one
 func (release *Release) ValidateSafeRelease(s3c aws.S3API, resources *ReleaseResources) error {
 	if len(resources.PreviousASGs) == 0 {
 		// If there are no currently deployed ASGs then we can ignore this check
@@ -61,13 +68,33 @@ func (release *Release) ValidateSafeRelease(s3c aws.S3API, resources *ReleaseRes
 	previousRelease.Release.SetDefaults(release.AwsRegion, release.AwsAccountID, "coinbase-odin-")
 	previousRelease.SetDefaults()
 
-	// return an error for valid services
-	return release.validateSafeRelease(&previousRelease)
+	// 10. AMI, the release is only updated with the resolved image after this check
+	var imageErr error
+	if resources.Image != nil && previousRelease.ImageID != nil {
+		if res := safeStr(resources.Image.ImageID, previousRelease.ImageID); res != nil {
+			imageErr = fmt.Errorf("SafeRelease Error: AMI different %v", *res)
+		}
+	}
+
+	sre := release.DiffSafeRelease(&previousRelease)
+	if imageErr != nil {
+		if sre == nil {
+			sre = &SafeReleaseError{Services: map[string]*SafeReleaseServiceError{}}
+		}
+		sre.Image = imageErr
+	}
+
+	if sre != nil {
+		return sre
+	}
+
+	return nil
 }
 
 type SafeReleaseError struct {
 	Subnets        error
 	Timeout        error
+	Image          error
 	AllServices    error
 	MissingService error
 
@@ -79,9 +106,10 @@ type SafeReleaseServiceError struct {
 	Profile                  error
 	ELBs                     error
 	TargetGroups             error
-	EBSVolumeSize            error
-	EBSVolumeType            error
-	EBSDeviceName            error
+	BlockDevices             error
+	MetadataOptions          error
+	LaunchOptions            error
+	LaunchTemplate           error
 	AssociatePublicIpAddress error
 	InstanceType             error
 	MinSize                  error
@@ -90,45 +118,82 @@ type SafeReleaseServiceError struct {
 	DefaultCooldown          error
 	HealthCheckGracePeriod   error
 	Spread                   error
+
+	HealthCheckType                  error
+	TerminationPolicies              error
+	CapacityRebalance                error
+	MaxInstanceLifetime              error
+	NewInstancesProtectedFromScaleIn error
 }
 
 // Prints the list of safe release errors
 func (sre *SafeReleaseError) Error() string {
 	errstr := ""
-	errstr = appendError(errstr, sre.Subnets)
-	errstr = appendError(errstr, sre.Timeout)
-	errstr = appendError(errstr, sre.AllServices)
-	errstr = appendError(errstr, sre.MissingService)
-	for _, srse := range sre.Services {
-		errstr = appendError(errstr, srse.SecurityGroups)
-		errstr = appendError(errstr, srse.Profile)
-		errstr = appendError(errstr, srse.ELBs)
-		errstr = appendError(errstr, srse.TargetGroups)
-		errstr = appendError(errstr, srse.EBSVolumeSize)
-		errstr = appendError(errstr, srse.EBSVolumeType)
-		errstr = appendError(errstr, srse.EBSDeviceName)
-		errstr = appendError(errstr, srse.AssociatePublicIpAddress)
-		errstr = appendError(errstr, srse.InstanceType)
-		errstr = appendError(errstr, srse.MinSize)
-		errstr = appendError(errstr, srse.MaxSize)
-		errstr = appendError(errstr, srse.MaxTerminations)
-		errstr = appendError(errstr, srse.DefaultCooldown)
-		errstr = appendError(errstr, srse.HealthCheckGracePeriod)
-		errstr = appendError(errstr, srse.Spread)
+	for _, diff := range sre.Differences() {
+		errstr = fmt.Sprintf("%s\n%s", errstr, diff)
 	}
 
 	return errstr
 }
 
-func appendError(errstr string, err error) string {
-	if err != nil {
-		errstr = fmt.Sprintf("%s\n%s", errstr, err.Error())
+// Differences returns each safe release error message, services in name order
+func (sre *SafeReleaseError) Differences() []string {
+	errs := []error{sre.Subnets, sre.Timeout, sre.Image, sre.AllServices, sre.MissingService}
+
+	serviceNames := []string{}
+	for name := range sre.Services {
+		serviceNames = append(serviceNames, name)
+	}
+	sort.Strings(serviceNames)
+
+	for _, name := range serviceNames {
+		srse := sre.Services[name]
+		errs = append(errs,
+			srse.SecurityGroups,
+			srse.Profile,
+			srse.ELBs,
+			srse.TargetGroups,
+			srse.BlockDevices,
+			srse.MetadataOptions,
+			srse.LaunchOptions,
+			srse.LaunchTemplate,
+			srse.AssociatePublicIpAddress,
+			srse.InstanceType,
+			srse.MinSize,
+			srse.MaxSize,
+			srse.MaxTerminations,
+			srse.DefaultCooldown,
+			srse.HealthCheckGracePeriod,
+			srse.Spread,
+			srse.HealthCheckType,
+			srse.TerminationPolicies,
+			srse.CapacityRebalance,
+			srse.MaxInstanceLifetime,
+			srse.NewInstancesProtectedFromScaleIn,
+		)
+	}
+
+	diffs := []string{}
+	for _, err := range errs {
+		if err != nil {
+			diffs = append(diffs, err.Error())
+		}
 	}
 
-	return errstr
+	return diffs
 }
 
 func (release *Release) validateSafeRelease(previousRelease *Release) error {
+	if sre := release.DiffSafeRelease(previousRelease); sre != nil {
+		return sre
+	}
+
+	return nil
+}
+
+// DiffSafeRelease compares this release to a previous release with the same checks as ValidateSafeRelease
+// It returns nil if there are no differences
+func (release *Release) DiffSafeRelease(previousRelease *Release) *SafeReleaseError {
 	sre := &SafeReleaseError{
 		Services: map[string]*SafeReleaseServiceError{},
 	}
@@ -142,11 +207,19 @@ func (release *Release) validateSafeRelease(previousRelease *Release) error {
 		sre.Timeout = fmt.Errorf("SafeRelease Error: Timeout different %v", *res)
 	}
 
+	// Retired services are removed on purpose
+	prevServices := map[string]*Service{}
+	for name, service := range previousRelease.Services {
+		if !release.IsRetired(name) {
+			prevServices[name] = service
+		}
+	}
+
 	// This will add errors to the sre
-	validateSafeServices(sre, release.Services, previousRelease.Services)
+	validateSafeServices(sre, release.Services, prevServices)
 
 	// Check whether an error was found and return if it has
-	if sre.Error() == "" {
+	if len(sre.Differences()) == 0 {
 		return nil
 	}
 
@@ -192,17 +265,24 @@ func validateSafeService(serviceName string, service *Service, prevService *Serv
 		srse.TargetGroups = fmt.Errorf("SafeRelease Error(%v): TargetGroups different %v", serviceName, *res)
 	}
 
-	// 5. EBS information
-	if res := safeInt64(service.EBSVolumeSize, prevService.EBSVolumeSize); res != nil {
-		srse.EBSVolumeSize = fmt.Errorf("SafeRelease Error(%v): EBSVolumeSize different %v", serviceName, *res)
+	// 5. EBS information, the EBS attributes are compared as the block device they define
+	if res := safeUnorderedStrList(blockDeviceStrs(service.blockDevices()), blockDeviceStrs(prevService.blockDevices())); res != nil {
+		srse.BlockDevices = fmt.Errorf("SafeRelease Error(%v): BlockDevices different %v", serviceName, *res)
 	}
 
-	if res := safeStr(service.EBSVolumeType, prevService.EBSVolumeType); res != nil {
-		srse.EBSVolumeType = fmt.Errorf("SafeRelease Error(%v): EBSVolumeType different %v", serviceName, *res)
+	// Tightening the instance metadata options is safe, loosening them is not
+	if loosened := service.MetadataOptions.Loosened(prevService.MetadataOptions); len(loosened) > 0 {
+		srse.MetadataOptions = fmt.Errorf("SafeRelease Error(%v): MetadataOptions loosened %v", serviceName, strings.Join(loosened, ", "))
 	}
 
-	if res := safeStr(service.EBSDeviceName, prevService.EBSDeviceName); res != nil {
-		srse.EBSDeviceName = fmt.Errorf("SafeRelease Error(%v): EBSDeviceName different %v", serviceName, *res)
+	// 8. Launch options
+	if res := safeStr(launchOptionsStr(service.LaunchOptions), launchOptionsStr(prevService.LaunchOptions)); res != nil {
+		srse.LaunchOptions = fmt.Errorf("SafeRelease Error(%v): LaunchOptions different %v", serviceName, *res)
+	}
+
+	// 9. LaunchTemplate
+	if res := safeBool(&service.LaunchTemplate, &prevService.LaunchTemplate); res != nil {
+		srse.LaunchTemplate = fmt.Errorf("SafeRelease Error(%v): LaunchTemplate different %v", serviceName, *res)
 	}
 
 	// 6. AssociatePublicIpAddress
@@ -244,6 +324,27 @@ func validateSafeAutoscaling(srse *SafeReleaseServiceError, serviceName string,
 	if res := safeFloat64(as.Spread, prevAs.Spread); res != nil {
 		srse.Spread = fmt.Errorf("SafeRelease Error(%v): Spread different %v", serviceName, *res)
 	}
+
+	if res := safeStr(as.HealthCheckType, prevAs.HealthCheckType); res != nil {
+		srse.HealthCheckType = fmt.Errorf("SafeRelease Error(%v): HealthCheckType different %v", serviceName, *res)
+	}
+
+	// Termination policies are evaluated in order
+	if res := safeStr(to.Strp(strings.Join(to.StrSlice(as.TerminationPolicies), ",")), to.Strp(strings.Join(to.StrSlice(prevAs.TerminationPolicies), ","))); res != nil {
+		srse.TerminationPolicies = fmt.Errorf("SafeRelease Error(%v): TerminationPolicies different %v", serviceName, *res)
+	}
+
+	if res := safeBool(as.CapacityRebalance, prevAs.CapacityRebalance); res != nil {
+		srse.CapacityRebalance = fmt.Errorf("SafeRelease Error(%v): CapacityRebalance different %v", serviceName, *res)
+	}
+
+	if res := safeInt64(as.MaxInstanceLifetime, prevAs.MaxInstanceLifetime); res != nil {
+		srse.MaxInstanceLifetime = fmt.Errorf("SafeRelease Error(%v): MaxInstanceLifetime different %v", serviceName, *res)
+	}
+
+	if res := safeBool(as.NewInstancesProtectedFromScaleIn, prevAs.NewInstancesProtectedFromScaleIn); res != nil {
+		srse.NewInstancesProtectedFromScaleIn = fmt.Errorf("SafeRelease Error(%v): NewInstancesProtectedFromScaleIn different %v", serviceName, *res)
+	}
 }
 
 ////
@@ -367,6 +468,23 @@ func safeUnorderedStrList(s1 []*string, s2 []*string) *string {
 	return nil
 }
 
+func blockDeviceStrs(bds []*BlockDevice) []*string {
+	strs := []*string{}
+	for _, bd := range bds {
+		if bd == nil {
+			continue
+		}
+
+		b, err := json.Marshal(bd)
+		if err != nil {
+			continue
+		}
+
+		strs = append(strs, to.Strp(string(b)))
+	}
+	return strs
+}
+
 func serviceMapKeys(sm map[string]*Service) []*string {
 	strSlice := []*string{}
 	for serviceName, _ := range sm {
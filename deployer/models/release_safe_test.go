@@ -3,6 +3,7 @@ package models
 import (
 	"testing"
 
+	"github.com/coinbase/odin/aws/ami"
 	"github.com/coinbase/odin/aws/asg"
 	"github.com/coinbase/step/utils/to"
 	"github.com/stretchr/testify/assert"
@@ -29,6 +30,32 @@ func Test_Release_ValidateSafeRelease_Works(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func Test_Release_ValidateSafeRelease_Image(t *testing.T) {
+	release := MockRelease(t)
+	MockPrepareRelease(release)
+
+	awsc := MockAwsClients(release)
+
+	previousRelease := MockRelease(t)
+	previousRelease.ReleaseID = to.Strp("prevReleaseID")
+	previousRelease.ImageID = to.Strp("ami-000001")
+	AddReleaseS3Objects(awsc, previousRelease)
+
+	resources := &ReleaseResources{
+		Image:             &ami.Image{ImageID: to.Strp("ami-000001")},
+		PreviousReleaseID: previousRelease.ReleaseID,
+		PreviousASGs:      map[string]*asg.ASG{"a": nil},
+	}
+
+	assert.NoError(t, release.ValidateSafeRelease(awsc.S3, resources))
+
+	resources.Image.ImageID = to.Strp("ami-000002")
+	err := release.ValidateSafeRelease(awsc.S3, resources)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "SafeRelease Error: AMI different")
+	}
+}
+
 func Test_Release_validateSafeRelease_Works(t *testing.T) {
 	release := MockRelease(t)
 	previousRelease := MockRelease(t)
@@ -82,6 +109,32 @@ func Test_Release_validateSafeRelease_Service(t *testing.T) {
 	validateSafeErrorTest(t, release, "Profile")
 }
 
+func Test_Release_validateSafeRelease_BlockDevices(t *testing.T) {
+	release := MockRelease(t)
+	release.Services["web"].EBSVolumeSize = to.Int64p(64)
+
+	validateSafeErrorTest(t, release, "BlockDevices")
+
+	release = MockRelease(t)
+	release.Services["web"].BlockDevices = []*BlockDevice{
+		&BlockDevice{DeviceName: to.Strp("/dev/xvdb"), VolumeSize: to.Int64p(500)},
+	}
+
+	validateSafeErrorTest(t, release, "BlockDevices")
+
+	// Moving the EBS attributes to the same block device is safe
+	release = MockRelease(t)
+	previous := MockRelease(t)
+	release.Services["web"].EBSVolumeSize = nil
+	release.Services["web"].BlockDevices = []*BlockDevice{
+		&BlockDevice{DeviceName: to.Strp("/dev/xvda"), VolumeSize: to.Int64p(120)},
+	}
+	release.SetDefaults()
+	previous.SetDefaults()
+
+	assert.NoError(t, release.validateSafeRelease(previous))
+}
+
 func Test_Release_validateSafeRelease_Autoscaling(t *testing.T) {
 	// Autoscaling
 
@@ -162,3 +215,70 @@ func validateSafeErrorTest(t *testing.T, release *Release, errStr string) {
 		assert.Regexp(t, errStr, err.Error())
 	}
 }
+
+func Test_Release_validateSafeRelease_MetadataOptions(t *testing.T) {
+	release := MockRelease(t)
+	release.Services["web"].MetadataOptions = &MetadataOptions{HttpPutResponseHopLimit: to.Int64p(2)}
+
+	validateSafeErrorTest(t, release, "MetadataOptions")
+
+	// Tightening is safe
+	release = MockRelease(t)
+	previous := MockRelease(t)
+	release.Services["web"].MetadataOptions = &MetadataOptions{HttpTokens: to.Strp("required")}
+	release.SetDefaults()
+	previous.SetDefaults()
+
+	assert.NoError(t, release.validateSafeRelease(previous))
+
+	// Back to the defaults is loosening
+	err := previous.validateSafeRelease(release)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "MetadataOptions")
+	}
+}
+
+func Test_Release_validateSafeRelease_LaunchOptions(t *testing.T) {
+	release := MockRelease(t)
+	release.Services["web"].LaunchOptions = &LaunchOptions{KeyName: to.Strp("deploy")}
+
+	validateSafeErrorTest(t, release, "LaunchOptions")
+
+	// Empty options are the same as none
+	release = MockRelease(t)
+	previous := MockRelease(t)
+	release.Services["web"].LaunchOptions = &LaunchOptions{}
+	release.SetDefaults()
+	previous.SetDefaults()
+
+	assert.NoError(t, release.validateSafeRelease(previous))
+}
+
+func Test_Release_validateSafeRelease_LaunchTemplate(t *testing.T) {
+	release := MockRelease(t)
+	release.Services["web"].LaunchTemplate = true
+
+	validateSafeErrorTest(t, release, "LaunchTemplate")
+}
+
+func Test_Release_validateSafeRelease_AutoscalingLifecycle(t *testing.T) {
+	release := MockRelease(t)
+	release.Services["web"].Autoscaling.HealthCheckType = to.Strp("EC2")
+	validateSafeErrorTest(t, release, "HealthCheckType")
+
+	release = MockRelease(t)
+	release.Services["web"].Autoscaling.TerminationPolicies = []*string{to.Strp("OldestInstance")}
+	validateSafeErrorTest(t, release, "TerminationPolicies")
+
+	release = MockRelease(t)
+	release.Services["web"].Autoscaling.CapacityRebalance = to.Boolp(true)
+	validateSafeErrorTest(t, release, "CapacityRebalance")
+
+	release = MockRelease(t)
+	release.Services["web"].Autoscaling.MaxInstanceLifetime = to.Int64p(604800)
+	validateSafeErrorTest(t, release, "MaxInstanceLifetime")
+
+	release = MockRelease(t)
+	release.Services["web"].Autoscaling.NewInstancesProtectedFromScaleIn = to.Boolp(true)
+	validateSafeErrorTest(t, release, "NewInstancesProtectedFromScaleIn")
+}
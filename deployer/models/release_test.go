@@ -3,6 +3,7 @@ package models
 import (
 	"testing"
 
+	"github.com/coinbase/odin/aws/ami"
 	"github.com/coinbase/step/utils/to"
 	"github.com/stretchr/testify/assert"
 )
@@ -40,3 +41,46 @@ func Test_SetDefaults_Sets_WaitForHealthy(t *testing.T) {
 	MockPrepareRelease(r)
 	assert.Equal(t, 120, *r.WaitForHealthy)
 }
+
+func Test_Release_Validate_ImageSelector(t *testing.T) {
+	r := MockRelease(t)
+	MockPrepareRelease(r)
+	assert.NoError(t, r.validateImage())
+
+	r.ImageSelector = &ami.Selector{Tags: map[string]string{"Name": "ubuntu-base"}, Latest: true}
+	assert.Error(t, r.validateImage())
+
+	r.Image = nil
+	assert.NoError(t, r.validateImage())
+
+	r.ImageSelector = &ami.Selector{}
+	assert.Error(t, r.validateImage())
+
+	r.ImageSelector = nil
+	assert.Error(t, r.validateImage())
+}
+
+func Test_Release_Validate_TeardownStrategy(t *testing.T) {
+	r := MockRelease(t)
+	r.TeardownStrategy = to.Strp("Drain")
+	r.LifeCycleHooks = map[string]*LifeCycleHook{
+		"TermingHook": &LifeCycleHook{
+			Transistion:      to.Strp("autoscaling:EC2_INSTANCE_TERMINATING"),
+			Role:             to.Strp("role"),
+			SNS:              to.Strp("sns"),
+			HeartbeatTimeout: to.Int64p(300),
+		},
+	}
+	MockPrepareRelease(r)
+
+	assert.NoError(t, r.validateTeardownStrategy())
+
+	r.LifeCycleHooks["TermingHook"].HeartbeatTimeout = to.Int64p(7200)
+	assert.Error(t, r.validateTeardownStrategy())
+
+	r.TeardownStrategy = to.Strp("Delete")
+	assert.NoError(t, r.validateTeardownStrategy())
+
+	r.TeardownStrategy = to.Strp("Wait")
+	assert.Error(t, r.validateTeardownStrategy())
+}
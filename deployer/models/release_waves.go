@@ -0,0 +1,146 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/step/utils/to"
+)
+
+// Waves returns the service names in the order their ASGs are created,
+// a service is in the wave after the last of the services it depends_on, and services after every job
+func (release *Release) Waves() ([][]string, error) {
+	for name, service := range release.Services {
+		if service == nil {
+			continue
+		}
+
+		for _, dep := range service.DependsOn {
+			if _, ok := release.Services[to.Strs(dep)]; !ok {
+				return nil, fmt.Errorf("Service %v depends_on unknown service %v", name, to.Strs(dep))
+			}
+		}
+	}
+
+	waves := [][]string{}
+	placed := map[string]bool{}
+
+	for len(placed) < len(release.Services) {
+		wave := []string{}
+		for name := range release.Services {
+			if !placed[name] && release.dependenciesIn(name, placed) {
+				wave = append(wave, name)
+			}
+		}
+
+		if len(wave) == 0 {
+			remaining := []string{}
+			for name := range release.Services {
+				if !placed[name] {
+					remaining = append(remaining, name)
+				}
+			}
+			sort.Strings(remaining)
+			return nil, fmt.Errorf("Services depends_on has a cycle between %v", strings.Join(remaining, ", "))
+		}
+
+		sort.Strings(wave)
+		for _, name := range wave {
+			placed[name] = true
+		}
+
+		waves = append(waves, wave)
+	}
+
+	return waves, nil
+}
+
+// ActiveWave returns the 1-based index of the first wave that is not healthy and the number of waves
+func (release *Release) ActiveWave() (int, int) {
+	waves, err := release.Waves()
+	if err != nil {
+		return 0, 0
+	}
+
+	for i, wave := range waves {
+		for _, name := range wave {
+			if service := release.Services[name]; service != nil && !service.Healthy {
+				return i + 1, len(waves)
+			}
+		}
+	}
+
+	return len(waves), len(waves)
+}
+
+// ReadyServices returns the services without an ASG whose dependencies are all healthy
+func (release *Release) ReadyServices() []*Service {
+	healthy := map[string]bool{}
+	for name, service := range release.Services {
+		// Carried services are already running
+		if service != nil && (!release.Deploys(name) || (service.CreatedASG != nil && service.Healthy)) {
+			healthy[name] = true
+		}
+	}
+
+	names := []string{}
+	for name, service := range release.Services {
+		if service != nil && service.CreatedASG == nil && release.Deploys(name) && release.dependenciesIn(name, healthy) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	ready := []*Service{}
+	for _, name := range names {
+		ready = append(ready, release.Services[name])
+	}
+
+	return ready
+}
+
+// CreateReadyResources creates the ASGs of the next wave once the services they depend on are healthy
+func (release *Release) CreateReadyResources(asgc aws.ASGAPI, cwc aws.CWAPI, ec2c aws.EC2API) error {
+	for _, service := range release.ReadyServices() {
+		if err := service.CreateResources(asgc, cwc, ec2c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dependencies returns the services that must be healthy before the named service is created
+func (release *Release) dependencies(name string) []string {
+	service := release.Services[name]
+	if service == nil {
+		return []string{}
+	}
+
+	deps := to.StrSlice(service.DependsOn)
+	if service.IsJob() {
+		return deps
+	}
+
+	// Services are gated on every job
+	for jobName, job := range release.Services {
+		if job != nil && job.IsJob() {
+			deps = append(deps, jobName)
+		}
+	}
+
+	return deps
+}
+
+// dependenciesIn returns true if every dependency of the named service is in the set
+func (release *Release) dependenciesIn(name string, set map[string]bool) bool {
+	for _, dep := range release.dependencies(name) {
+		if !set[dep] {
+			return false
+		}
+	}
+
+	return true
+}
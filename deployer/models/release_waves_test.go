@@ -0,0 +1,77 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Release_Waves(t *testing.T) {
+	r := MockRelease(t)
+	r.Services = map[string]*Service{
+		"migrator": &Service{},
+		"api":      &Service{DependsOn: []*string{to.Strp("migrator")}},
+		"web":      &Service{DependsOn: []*string{to.Strp("migrator")}},
+		"worker":   &Service{DependsOn: []*string{to.Strp("api"), to.Strp("migrator")}},
+	}
+
+	waves, err := r.Waves()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"migrator"}, {"api", "web"}, {"worker"}}, waves)
+
+	r.Services["migrator"].DependsOn = []*string{to.Strp("worker")}
+	_, err = r.Waves()
+	if assert.Error(t, err) {
+		assert.Equal(t, "Services depends_on has a cycle between api, migrator, web, worker", err.Error())
+	}
+
+	r.Services["migrator"].DependsOn = []*string{to.Strp("db")}
+	_, err = r.Waves()
+	if assert.Error(t, err) {
+		assert.Equal(t, "Service migrator depends_on unknown service db", err.Error())
+	}
+}
+
+func Test_Release_ValidateServices_DependsOnCycle(t *testing.T) {
+	r := MockRelease(t)
+	r.Services["web"].DependsOn = []*string{to.Strp("web")}
+	MockPrepareRelease(r)
+
+	err := r.ValidateServices()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "cycle")
+	}
+}
+
+func Test_Release_CreateResources_Waves(t *testing.T) {
+	r := MockRelease(t)
+	r.Services["worker"] = MockRelease(t).Services["web"]
+	r.Services["worker"].DependsOn = []*string{to.Strp("web")}
+	MockPrepareRelease(r)
+
+	awsc := MockAwsClients(r)
+
+	assert.NoError(t, r.CreateResources(awsc.ASG, awsc.CW, awsc.EC2))
+	assert.NotNil(t, r.Services["web"].CreatedASG)
+	assert.Nil(t, r.Services["worker"].CreatedASG)
+
+	wave, waves := r.ActiveWave()
+	assert.Equal(t, 1, wave)
+	assert.Equal(t, 2, waves)
+
+	// worker waits for web to be healthy
+	assert.Equal(t, 0, len(r.ReadyServices()))
+	assert.NoError(t, r.UpdateHealthy(awsc.ASG, awsc.ELB, awsc.ALB, awsc.S3))
+	assert.False(t, *r.Healthy)
+
+	r.Services["web"].Healthy = true
+	assert.Equal(t, []*Service{r.Services["worker"]}, r.ReadyServices())
+
+	assert.NoError(t, r.CreateReadyResources(awsc.ASG, awsc.CW, awsc.EC2))
+	assert.NotNil(t, r.Services["worker"].CreatedASG)
+	assert.Equal(t, 0, len(r.ReadyServices()))
+
+	wave, _ = r.ActiveWave()
+	assert.Equal(t, 2, wave)
+}
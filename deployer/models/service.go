@@ -1,18 +1,23 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/coinbase/odin/aws"
 	"github.com/coinbase/odin/aws/alb"
 	"github.com/coinbase/odin/aws/asg"
 	"github.com/coinbase/odin/aws/elb"
 	"github.com/coinbase/odin/aws/iam"
+	"github.com/coinbase/odin/aws/keypair"
 	"github.com/coinbase/odin/aws/lc"
+	"github.com/coinbase/odin/aws/lt"
 	"github.com/coinbase/odin/aws/pg"
 	"github.com/coinbase/odin/aws/sg"
 	"github.com/coinbase/step/utils/is"
@@ -29,6 +34,8 @@ type HealthReport struct {
 	Launching      *int     `json:"launching,omitempty"`       // Number of instances that have been created
 	Terminating    *int     `json:"terminating,omitempty"`     // Number of instances that are Terminating
 	TerminatingIDs []string `json:"terminating_ids,omitempty"` // Instance IDs that are Terminating
+	WaitingIDs     []string `json:"waiting_ids,omitempty"`     // Instance IDs paused by a launching lifecycle hook
+	WaitingOn      *string  `json:"waiting_on,omitempty"`      // The launching lifecycle hooks instances are waiting on
 
 	DesiredCapacity *int64 `json:"desired_capacity,omitempty"` // The current desired capacity goal
 	MinSize         *int64 `json:"min_size,omitempty"`         // The current min size
@@ -51,6 +58,19 @@ type Service struct {
 	SecurityGroups []*string          `json:"security_groups,omitempty"`
 	Tags           map[string]*string `json:"tags,omitempty"`
 
+	// Kind is "service" (default) or "job", a job runs MinSize instances until each writes its result to S3
+	Kind *string `json:"kind,omitempty"`
+
+	// JobComplete is set once every job instance succeeded and the job ASG is scaled to zero
+	JobComplete bool `json:"job_complete,omitempty"`
+
+	// DependsOn are the services that must be healthy before this service's ASG is created
+	DependsOn []*string `json:"depends_on,omitempty"`
+
+	// PropagateTags copies the custom tags to "instances" (default), "volumes" or "all", volumes requires LaunchTemplate.
+	// The Odin tags are always copied to the instances
+	PropagateTags *string `json:"propagate_tags,omitempty"`
+
 	// Create Resources
 	InstanceType *string            `json:"instance_type,omitempty"`
 	Autoscaling  *AutoScalingConfig `json:"autoscaling,omitempty"`
@@ -59,11 +79,23 @@ type Service struct {
 	// Strategy contains all the information about how to scale
 	strategy *Strategy
 
-	// EBS
+	// EBS, a single volume. Use BlockDevices for more than one or for other volume attributes
 	EBSVolumeSize *int64  `json:"ebs_volume_size,omitempty"`
 	EBSVolumeType *string `json:"ebs_volume_type,omitempty"`
 	EBSDeviceName *string `json:"ebs_device_name,omitempty"`
 
+	BlockDevices []*BlockDevice `json:"block_devices,omitempty"`
+
+	// Instance metadata service options, defaulted from and no looser than the OdinConfig
+	MetadataOptions *MetadataOptions `json:"metadata_options,omitempty"`
+
+	// Monitoring, credit specification, key pair and EBS optimization
+	LaunchOptions *LaunchOptions `json:"launch_options,omitempty"`
+
+	// LaunchTemplate launches instances with a launch template instead of a launch configuration
+	// It is required for settings launch configurations do not support, e.g. a block device KmsKeyID
+	LaunchTemplate bool `json:"launch_template,omitempty"`
+
 	// Placement Group
 	PlacementGroupName           *string `json:"placement_group_name,omitempty"`
 	PlacementGroupPartitionCount *int64  `json:"placement_group_partition_count,omitempty"`
@@ -72,6 +104,10 @@ type Service struct {
 	// Dedicated tenancy or neighbors allowed
 	PlacementTenancy *string `json:"placement_tenancy,omitempty"`
 
+	// SuspendPreviousScaling stops the previous ASG scaling in while this release deploys, e.g. so CPU alarms
+	// do not terminate workers mid-job. The processes are resumed if the release fails
+	SuspendPreviousScaling bool `json:"suspend_previous_scaling,omitempty"`
+
 	// Network
 	AssociatePublicIpAddress *bool `json:"associate_public_ip_address,omitempty"`
 
@@ -82,6 +118,13 @@ type Service struct {
 	CreatedASG              *string `json:"created_asg,omitempty"`
 	PreviousDesiredCapacity *int64  `json:"previous_desired_capacity,omitempty"`
 
+	// CarriedASG is the existing ASG a partial release keeps for a service it does not deploy
+	CarriedASG *string `json:"carried_asg,omitempty"`
+
+	// SuspendedProcesses are the processes Odin suspends on the previous ASG,
+	// processes that were already suspended are left out so a failure does not resume them
+	SuspendedProcesses []*string `json:"suspended_processes,omitempty"`
+
 	// What is Healthy
 	HealthReport *HealthReport `json:"healthy_report,omitempty"`
 	Healthy      bool
@@ -127,8 +170,8 @@ func (service *Service) ServiceID() *string {
 		return nil
 	}
 
-	tf := strings.Replace(service.CreatedAt().UTC().Format(time.RFC3339), ":", "-", -1)
-	return to.Strp(fmt.Sprintf("%v-%v-%v-%v", *service.ProjectName(), *service.ConfigName(), tf, *service.ServiceName))
+	prefix := ReleaseServiceIDPrefix(*service.ProjectName(), *service.ConfigName(), *service.CreatedAt())
+	return to.Strp(fmt.Sprintf("%v%v", prefix, *service.ServiceName))
 }
 
 // Subnets returns subnets
@@ -150,6 +193,7 @@ func (service *Service) UserData() *string {
 
 	templateARGs = append(templateARGs, "{{SHARED_PROJECT_DIR}}", to.Strs(service.release.SharedProjectDir()))
 	templateARGs = append(templateARGs, "{{RELEASE_DIR}}", to.Strs(service.release.ReleaseDir()))
+	templateARGs = append(templateARGs, "{{JOB_DIR}}", to.Strs(service.JobDir()))
 
 	replacer := strings.NewReplacer(templateARGs...)
 
@@ -175,11 +219,63 @@ func (service *Service) SubnetIds() *string {
 func (service *Service) LifeCycleHookSpecs() []*autoscaling.LifecycleHookSpecification {
 	lcs := []*autoscaling.LifecycleHookSpecification{}
 	for _, lc := range service.LifeCycleHooks() {
-		lcs = append(lcs, lc.ToLifecycleHookSpecification())
+		spec := lc.ToLifecycleHookSpecification()
+		spec.NotificationMetadata = service.lifeCycleNotificationMetadata()
+		lcs = append(lcs, spec)
 	}
 	return lcs
 }
 
+// lifeCycleNotificationMetadata is sent with every lifecycle notification so consumers know where it came from
+func (service *Service) lifeCycleNotificationMetadata() *string {
+	metadata, _ := json.Marshal(map[string]*string{
+		"release_id":   service.ReleaseID(),
+		"service_name": service.ServiceName,
+	})
+	return to.Strp(string(metadata))
+}
+
+// launchingHookNames returns the sorted names of the launching lifecycle hooks,
+// only those that abandon the instance when they time out if abandoning is true
+func (service *Service) launchingHookNames(abandoning bool) []string {
+	names := []string{}
+	for name, lc := range service.LifeCycleHooks() {
+		if lc == nil || to.Strs(lc.Transistion) != "autoscaling:EC2_INSTANCE_LAUNCHING" {
+			continue
+		}
+
+		// AWS defaults to ABANDON
+		if abandoning && to.Strs(lc.DefaultResult) == "CONTINUE" {
+			continue
+		}
+
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// abandonedIDs returns the terminating instances that were waiting on an abandoning launching hook at the last health check
+func (service *Service) abandonedIDs(all aws.Instances) []string {
+	if service.HealthReport == nil || len(service.launchingHookNames(true)) == 0 {
+		return []string{}
+	}
+
+	waiting := map[string]bool{}
+	for _, id := range service.HealthReport.WaitingIDs {
+		waiting[id] = true
+	}
+
+	ids := []string{}
+	for _, id := range all.TerminatingIDs() {
+		if waiting[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
 func (service *Service) errorPrefix() string {
 	if service.ServiceName == nil {
 		return fmt.Sprintf("Service Error:")
@@ -197,6 +293,18 @@ func (service *Service) SetDefaults(release *Release, serviceName string) {
 
 	service.ServiceName = &serviceName
 
+	// Release tags are defaults for the service tags
+	for key, value := range release.Tags {
+		if _, ok := service.Tags[key]; ok {
+			continue
+		}
+
+		if service.Tags == nil {
+			service.Tags = map[string]*string{}
+		}
+		service.Tags[key] = value
+	}
+
 	// Autoscaling Defaults
 	if service.Autoscaling == nil {
 		service.Autoscaling = &AutoScalingConfig{}
@@ -210,6 +318,12 @@ func (service *Service) SetDefaults(release *Release, serviceName string) {
 
 	service.Autoscaling.SetDefaults(service.ServiceID(), service.release.Timeout)
 
+	for _, bd := range service.BlockDevices {
+		if bd != nil {
+			bd.SetDefaults()
+		}
+	}
+
 	service.strategy = NewStrategy(service.Autoscaling, service.PreviousDesiredCapacity)
 }
 
@@ -230,6 +344,17 @@ func (service *Service) setHealthy(group *asg.ASG, instances aws.Instances) {
 		MinSize:         group.MinSize,
 	}
 
+	if waiting := group.LifecycleWaitingIDs(); len(waiting) > 0 {
+		service.HealthReport.WaitingIDs = waiting
+		// The ASG does not report which hook an instance waits on
+		hooks := service.launchingHookNames(false)
+		if len(hooks) == 1 {
+			service.HealthReport.WaitingOn = to.Strp(fmt.Sprintf("waiting on hook %v", hooks[0]))
+		} else {
+			service.HealthReport.WaitingOn = to.Strp(fmt.Sprintf("waiting on one of the hooks %v", strings.Join(hooks, ", ")))
+		}
+	}
+
 	// The Service is Healthy if
 	// the number of instances that are healthy is greater than or equal to the target
 	service.Healthy = int64(len(healthy)) >= service.strategy.TargetHealthy()
@@ -261,8 +386,14 @@ func (service *Service) Validate() error {
 		return fmt.Errorf("%v %v", service.errorPrefix(), err.Error())
 	}
 
-	if err := service.createLaunchConfigurationInput().Validate(); err != nil {
-		return fmt.Errorf("%v %v", service.errorPrefix(), err.Error())
+	if service.LaunchTemplate {
+		if err := service.createLaunchTemplateInput().Validate(); err != nil {
+			return fmt.Errorf("%v %v", service.errorPrefix(), err.Error())
+		}
+	} else {
+		if err := service.createLaunchConfigurationInput().Validate(); err != nil {
+			return fmt.Errorf("%v %v", service.errorPrefix(), err.Error())
+		}
 	}
 
 	return nil
@@ -286,6 +417,10 @@ func (service *Service) ValidateAttributes() error {
 		return err
 	}
 
+	if err := service.validateAutoscalingAttributes(); err != nil {
+		return err
+	}
+
 	// Must have security groups
 	if len(service.SecurityGroups) < 1 {
 		return fmt.Errorf("Security Groups must be included")
@@ -313,9 +448,122 @@ func (service *Service) ValidateAttributes() error {
 		return fmt.Errorf("Placement tenancy must be unset or set to 'default' or 'dedicated'.")
 	}
 
+	if err := service.validateBlockDevices(); err != nil {
+		return err
+	}
+
+	if err := service.validateTags(); err != nil {
+		return err
+	}
+
+	if err := service.validateKind(); err != nil {
+		return err
+	}
+
+	if mo := service.MetadataOptions; mo != nil {
+		if err := mo.ValidateAttributes(); err != nil {
+			return err
+		}
+
+		if to.Strs(mo.InstanceMetadataTags) == "enabled" && !service.LaunchTemplate {
+			return fmt.Errorf("MetadataOptions InstanceMetadataTags requires LaunchTemplate")
+		}
+	}
+
+	if lo := service.LaunchOptions; lo != nil {
+		if err := lo.ValidateAttributes(); err != nil {
+			return err
+		}
+
+		if lo.CreditSpecification != nil && !service.LaunchTemplate {
+			return fmt.Errorf("LaunchOptions CreditSpecification requires LaunchTemplate")
+		}
+	}
+
+	return nil
+}
+
+// validateAutoscalingAttributes validates the autoscaling config against the rest of the service
+func (service *Service) validateAutoscalingAttributes() error {
+	as := service.Autoscaling
+
+	if to.Strs(as.HealthCheckType) == "ELB" && len(service.ELBs) == 0 && len(service.TargetGroups) == 0 {
+		return fmt.Errorf("Autoscaling HealthCheckType ELB requires ELBs or TargetGroups")
+	}
+
+	if as.CapacityRebalance != nil && *as.CapacityRebalance && service.SpotPrice == nil {
+		return fmt.Errorf("Autoscaling CapacityRebalance requires SpotPrice")
+	}
+
+	for _, tp := range as.TerminationPolicies {
+		switch {
+		case *tp == "OldestLaunchConfiguration" && service.LaunchTemplate:
+			return fmt.Errorf("Autoscaling TerminationPolicy OldestLaunchConfiguration cannot be used with LaunchTemplate")
+		case *tp == "OldestLaunchTemplate" && !service.LaunchTemplate:
+			return fmt.Errorf("Autoscaling TerminationPolicy OldestLaunchTemplate requires LaunchTemplate")
+		}
+	}
+
+	return nil
+}
+
+func (service *Service) validateBlockDevices() error {
+	if len(service.BlockDevices) == 0 {
+		return nil
+	}
+
+	if service.EBSVolumeSize != nil || service.EBSVolumeType != nil || service.EBSDeviceName != nil {
+		return fmt.Errorf("BlockDevices cannot be used with EBSVolumeSize, EBSVolumeType or EBSDeviceName")
+	}
+
+	deviceNames := []*string{}
+	for _, bd := range service.BlockDevices {
+		if bd == nil {
+			return fmt.Errorf("BlockDevice is nil")
+		}
+
+		if err := bd.ValidateAttributes(); err != nil {
+			return err
+		}
+
+		if bd.KmsKeyID != nil && !service.LaunchTemplate {
+			return fmt.Errorf("BlockDevice(%v) KmsKeyID requires LaunchTemplate", *bd.DeviceName)
+		}
+
+		deviceNames = append(deviceNames, bd.DeviceName)
+	}
+
+	if !is.UniqueStrp(deviceNames) {
+		return fmt.Errorf("BlockDevice DeviceNames must be unique")
+	}
+
 	return nil
 }
 
+// blockDevices returns the block devices of the service, including the one defined with the EBS attributes
+func (service *Service) blockDevices() []*BlockDevice {
+	if service.EBSVolumeSize == nil {
+		return service.BlockDevices
+	}
+
+	// Same defaults as lc.AddBlockDevice
+	bd := &BlockDevice{
+		DeviceName: service.EBSDeviceName,
+		VolumeSize: service.EBSVolumeSize,
+		VolumeType: service.EBSVolumeType,
+	}
+
+	if bd.DeviceName == nil {
+		bd.DeviceName = to.Strp("/dev/xvda")
+	}
+
+	if bd.VolumeType == nil {
+		bd.VolumeType = to.Strp("gp2")
+	}
+
+	return append([]*BlockDevice{bd}, service.BlockDevices...)
+}
+
 func (service *Service) validatePlacementGroupAttributes() error {
 	// if PlacementGroupName is not nil, then there must be a Strategy either cluster | spread | partition
 	// if the strategy is partition then there must be a partition count
@@ -389,11 +637,21 @@ func (service *Service) FetchResources(ec2 aws.EC2API, elbc aws.ELBAPI, albc aws
 		}
 	}
 
+	// Fetch Key Pair
+	var kp *keypair.KeyPair
+	if service.LaunchOptions != nil && service.LaunchOptions.KeyName != nil {
+		kp, err = keypair.Find(ec2, service.LaunchOptions.KeyName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &ServiceResources{
 		SecurityGroups: sgs,
 		ELBs:           elbs,
 		TargetGroups:   targetGroups,
 		Profile:        iamProfile,
+		KeyPair:        kp,
 	}, nil
 }
 
@@ -401,10 +659,19 @@ func (service *Service) FetchResources(ec2 aws.EC2API, elbc aws.ELBAPI, albc aws
 // Create Resources
 //////////
 
-// CreateResources creates the ASG and Launch configuration for the service
-func (service *Service) CreateResources(asgc aws.ASGAPI, cwc aws.CWAPI) error {
+// CreateResources creates the ASG and Launch configuration or Launch template for the service
+func (service *Service) CreateResources(asgc aws.ASGAPI, cwc aws.CWAPI, ec2c aws.EC2API) error {
+	if err := service.suspendPreviousScaling(asgc); err != nil {
+		return err
+	}
+
+	var err error
+	if service.LaunchTemplate {
+		err = service.createLaunchTemplate(ec2c)
+	} else {
+		err = service.createLaunchConfiguration(asgc)
+	}
 
-	err := service.createLaunchConfiguration(asgc)
 	if err != nil {
 		return err
 	}
@@ -430,11 +697,45 @@ func (service *Service) CreateResources(asgc aws.ASGAPI, cwc aws.CWAPI) error {
 	return nil
 }
 
+// previousASG returns the ASG of the previous release for the service, nil if there is none
+func (service *Service) previousASG() *asg.ASG {
+	if service.Resources == nil || service.Resources.PrevASG == nil {
+		return nil
+	}
+
+	return &asg.ASG{AutoScalingGroupName: service.Resources.PrevASG}
+}
+
+func (service *Service) suspendPreviousScaling(asgc aws.ASGAPI) error {
+	prev := service.previousASG()
+	if !service.SuspendPreviousScaling || prev == nil || len(service.SuspendedProcesses) == 0 {
+		return nil
+	}
+
+	return prev.SuspendProcesses(asgc, service.SuspendedProcesses)
+}
+
+func (service *Service) resumePreviousScaling(asgc aws.ASGAPI) error {
+	prev := service.previousASG()
+	if !service.SuspendPreviousScaling || prev == nil || len(service.SuspendedProcesses) == 0 {
+		return nil
+	}
+
+	return prev.ResumeProcesses(asgc, service.SuspendedProcesses)
+}
+
 func (service *Service) createInput() *asg.Input {
 	input := &asg.Input{&autoscaling.CreateAutoScalingGroupInput{}}
 
 	input.AutoScalingGroupName = service.ServiceID()
-	input.LaunchConfigurationName = service.ServiceID()
+	if service.LaunchTemplate {
+		input.LaunchTemplate = &autoscaling.LaunchTemplateSpecification{
+			LaunchTemplateName: service.ServiceID(),
+			Version:            to.Strp("$Latest"),
+		}
+	} else {
+		input.LaunchConfigurationName = service.ServiceID()
+	}
 
 	// Adjusted by strategy
 	input.MinSize = service.strategy.InitialMinSize()
@@ -444,6 +745,11 @@ func (service *Service) createInput() *asg.Input {
 	input.MaxSize = service.Autoscaling.MaxSize
 	input.DefaultCooldown = service.Autoscaling.DefaultCooldown
 	input.HealthCheckGracePeriod = service.Autoscaling.HealthCheckGracePeriod
+	input.HealthCheckType = service.Autoscaling.HealthCheckType
+	input.TerminationPolicies = service.Autoscaling.TerminationPolicies
+	input.CapacityRebalance = service.Autoscaling.CapacityRebalance
+	input.MaxInstanceLifetime = service.Autoscaling.MaxInstanceLifetime
+	input.NewInstancesProtectedFromScaleIn = service.Autoscaling.NewInstancesProtectedFromScaleIn
 
 	input.LoadBalancerNames = service.Resources.ELBs
 	input.TargetGroupARNs = service.Resources.TargetGroups
@@ -455,16 +761,17 @@ func (service *Service) createInput() *asg.Input {
 		input.PlacementGroup = service.PlacementGroupName
 	}
 
-	for key, value := range service.Tags {
-		input.AddTag(key, value)
+	tags := service.tags()
+	keys := []string{}
+	for key := range tags {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
 
-	input.AddTag("ProjectName", service.ProjectName())
-	input.AddTag("ConfigName", service.ConfigName())
-	input.AddTag("ServiceName", service.ServiceName)
-	input.AddTag("ReleaseID", service.ReleaseID())
-	input.AddTag("ReleaseUUID", service.ReleaseUUID())
-	input.AddTag("Name", service.ServiceID())
+	// The Odin tags always propagate so every instance has its Name and cost allocation tags
+	for _, key := range keys {
+		input.AddTag(key, tags[key], isOdinTagKey(key) || service.tagsToInstances())
+	}
 
 	input.SetDefaults()
 
@@ -501,6 +808,10 @@ func (service *Service) createLaunchConfigurationInput() *lc.LaunchConfigInput {
 		input.ImageId = service.Resources.Image
 		input.SecurityGroups = service.Resources.SecurityGroups
 		input.IamInstanceProfile = service.Resources.Profile
+
+		if service.Resources.EbsOptimized != nil {
+			input.EbsOptimized = service.Resources.EbsOptimized
+		}
 	}
 	input.InstanceType = service.InstanceType
 
@@ -510,10 +821,30 @@ func (service *Service) createLaunchConfigurationInput() *lc.LaunchConfigInput {
 
 	input.AddBlockDevice(service.EBSVolumeSize, service.EBSVolumeType, service.EBSDeviceName)
 
+	for _, bd := range service.BlockDevices {
+		input.BlockDeviceMappings = append(input.BlockDeviceMappings, bd.ToBlockDeviceMapping())
+	}
+
 	input.SpotPrice = service.SpotPrice
 
 	input.PlacementTenancy = service.PlacementTenancy
 
+	if service.MetadataOptions != nil {
+		input.MetadataOptions = service.MetadataOptions.ToInstanceMetadataOptions()
+	}
+
+	if lo := service.LaunchOptions; lo != nil {
+		if lo.DetailedMonitoring != nil {
+			input.InstanceMonitoring = &autoscaling.InstanceMonitoring{Enabled: lo.DetailedMonitoring}
+		}
+
+		if lo.EbsOptimized != nil {
+			input.EbsOptimized = lo.EbsOptimized
+		}
+
+		input.KeyName = lo.KeyName
+	}
+
 	return input
 }
 
@@ -527,6 +858,42 @@ func (service *Service) createLaunchConfiguration(asgc autoscalingiface.AutoScal
 	return nil
 }
 
+func (service *Service) createLaunchTemplateInput() *lt.LaunchTemplateInput {
+	input := lt.FromLaunchConfigInput(service.createLaunchConfigurationInput().CreateLaunchConfigurationInput)
+
+	// Launch configuration block devices cannot have a KMS key, so they are replaced
+	input.LaunchTemplateData.BlockDeviceMappings = nil
+	for _, bd := range service.blockDevices() {
+		input.LaunchTemplateData.BlockDeviceMappings = append(input.LaunchTemplateData.BlockDeviceMappings, bd.ToLaunchTemplateBlockDeviceMapping())
+	}
+
+	// InstanceMetadataTags is also only supported by launch templates
+	if service.MetadataOptions != nil {
+		input.LaunchTemplateData.MetadataOptions = service.MetadataOptions.ToLaunchTemplateInstanceMetadataOptions()
+	}
+
+	if service.LaunchOptions != nil && service.LaunchOptions.CreditSpecification != nil {
+		input.LaunchTemplateData.CreditSpecification = &ec2.CreditSpecificationRequest{CpuCredits: service.LaunchOptions.CreditSpecification}
+	}
+
+	// ASG tags are not propagated to volumes
+	if service.tagsToVolumes() {
+		input.LaunchTemplateData.TagSpecifications = service.volumeTagSpecifications()
+	}
+
+	return input
+}
+
+func (service *Service) createLaunchTemplate(ec2c aws.EC2API) error {
+	input := service.createLaunchTemplateInput()
+
+	if err := input.Create(ec2c); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (service *Service) createMetricsCollection(asgc aws.ASGAPI) error {
 	// Ref: https://docs.aws.amazon.com/sdk-for-go/api/service/autoscaling/#EnableMetricsCollectionInput
 	// If you omit this parameter (`Metrics`), all metrics are enabled which is desired.
@@ -568,6 +935,11 @@ func (service *Service) UpdateHealthy(asgc aws.ASGAPI, elbc aws.ELBAPI, albc aws
 
 	// Early exit and Halt if there are instances Terminating
 	if service.strategy.ReachedMaxTerminations(all) {
+		if abandoned := service.abandonedIDs(all); len(abandoned) > 0 {
+			err := fmt.Errorf("Lifecycle hook %v abandoned instances %v, %v", strings.Join(service.launchingHookNames(true), ", "), *service.ServiceName, strings.Join(abandoned, ","))
+			return &HaltError{err}
+		}
+
 		err := fmt.Errorf("Found terming instances %v, %v", *service.ServiceName, strings.Join(all.TerminatingIDs(), ","))
 		return &HaltError{err} // This will immediately stop deploying
 	}
@@ -643,6 +1015,11 @@ func (service *Service) SetMinDesiredCapacity(asgc aws.ASGAPI, minSize, desiredC
 
 // ResetDesiredCapacity sets the min and desired capacities to their final values
 func (service *Service) ResetDesiredCapacity(asgc aws.ASGAPI) error {
+	if service.IsJob() || service.IsCarried() {
+		// Completed jobs stay scaled to zero, carried ASGs are not changed
+		return nil
+	}
+
 	return service.SetMinDesiredCapacity(
 		asgc,
 		service.Autoscaling.MinSize,
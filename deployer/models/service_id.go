@@ -0,0 +1,80 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// A ServiceID is "<project>-<config>-<created at>-<service>", with the ":" in the created at time replaced by "-"
+// ASGs, launch configurations and alarms are all named starting with a ServiceID
+var serviceIDTimeRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}-\d{2}-\d{2}Z-`)
+
+// ProjectConfigServiceIDPrefix returns the prefix of every ServiceID for the project config
+func ProjectConfigServiceIDPrefix(projectName string, configName string) string {
+	return fmt.Sprintf("%v-%v-", projectName, configName)
+}
+
+// ReleaseServiceIDPrefix returns the prefix of the ServiceIDs of a release created at createdAt
+func ReleaseServiceIDPrefix(projectName string, configName string, createdAt time.Time) string {
+	tf := strings.Replace(createdAt.UTC().Format(time.RFC3339), ":", "-", -1)
+	return fmt.Sprintf("%v%v-", ProjectConfigServiceIDPrefix(projectName, configName), tf)
+}
+
+// HasProjectConfigServiceIDPrefix returns true if name starts with a ServiceID of the project config
+func HasProjectConfigServiceIDPrefix(name string, projectName string, configName string) bool {
+	prefix := ProjectConfigServiceIDPrefix(projectName, configName)
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+
+	// The created at time must follow the prefix, otherwise this is another project config with a similar name
+	return serviceIDTimeRegex.MatchString(name[len(prefix):])
+}
+
+// ServiceIDCreatedAt returns the created at time if name is exactly a ServiceID of the project config's service,
+// e.g. an ASG, launch configuration or launch template name, nil otherwise
+func ServiceIDCreatedAt(name string, projectName string, configName string, serviceName string) *time.Time {
+	return serviceIDCreatedAt(name, projectName, configName, serviceName, "")
+}
+
+// AlarmServiceIDCreatedAt returns the created at time if name is a scaling alarm "<ServiceID>-<policy type>[-<name>]"
+// of the project config's service, nil otherwise
+func AlarmServiceIDCreatedAt(name string, projectName string, configName string, serviceName string) *time.Time {
+	return serviceIDCreatedAt(name, projectName, configName, serviceName, fmt.Sprintf("-(%v|%v)(-.+)?", cpuScaleUp, cpuScaleDown))
+}
+
+func serviceIDCreatedAt(name string, projectName string, configName string, serviceName string, suffix string) *time.Time {
+	prefix := ProjectConfigServiceIDPrefix(projectName, configName)
+	if !strings.HasPrefix(name, prefix) {
+		return nil
+	}
+
+	re, err := regexp.Compile(fmt.Sprintf(`^(\d{4}-\d{2}-\d{2}T\d{2}-\d{2}-\d{2}Z)-%v%v$`, regexp.QuoteMeta(serviceName), suffix))
+	if err != nil {
+		return nil
+	}
+
+	match := re.FindStringSubmatch(name[len(prefix):])
+	if match == nil {
+		return nil
+	}
+
+	createdAt, err := time.Parse("2006-01-02T15-04-05Z", match[1])
+	if err != nil {
+		return nil
+	}
+
+	return &createdAt
+}
+
+// HasAnyPrefix returns true if name starts with any of the prefixes
+func HasAnyPrefix(name string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
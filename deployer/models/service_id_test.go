@@ -0,0 +1,38 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ServiceIDPrefixes(t *testing.T) {
+	createdAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Equal(t, "project-config-", ProjectConfigServiceIDPrefix("project", "config"))
+	assert.Equal(t, "project-config-2020-01-02T03-04-05Z-", ReleaseServiceIDPrefix("project", "config", createdAt))
+
+	assert.True(t, HasProjectConfigServiceIDPrefix("project-config-2020-01-02T03-04-05Z-web", "project", "config"))
+	assert.True(t, HasProjectConfigServiceIDPrefix("project-config-2020-01-02T03-04-05Z-web-cpu_scale_up", "project", "config"))
+
+	assert.False(t, HasProjectConfigServiceIDPrefix("project-config-staging-2020-01-02T03-04-05Z-web", "project", "config"))
+	assert.False(t, HasProjectConfigServiceIDPrefix("other-config-2020-01-02T03-04-05Z-web", "project", "config"))
+}
+
+func Test_ServiceIDCreatedAt(t *testing.T) {
+	createdAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Equal(t, createdAt, *ServiceIDCreatedAt("project-config-2020-01-02T03-04-05Z-web", "project", "config", "web"))
+	assert.Equal(t, createdAt, *ServiceIDCreatedAt("my-project-my-config-2020-01-02T03-04-05Z-web", "my-project", "my-config", "web"))
+
+	// The whole name must be the ServiceID
+	assert.Nil(t, ServiceIDCreatedAt("project-config-2020-01-02T03-04-05Z-web-cpu_scale_up", "project", "config", "web"))
+	assert.Nil(t, ServiceIDCreatedAt("team-project-config-2020-01-02T03-04-05Z-web", "project", "config", "web"))
+	assert.Nil(t, ServiceIDCreatedAt("project-config-2020-01-02T03-04-05Z-worker", "project", "config", "web"))
+	assert.Nil(t, ServiceIDCreatedAt("project-config-web-old-release", "project", "config", "web"))
+	assert.Nil(t, ServiceIDCreatedAt("project-config-2020-13-02T03-04-05Z-web", "project", "config", "web"))
+
+	assert.Equal(t, createdAt, *AlarmServiceIDCreatedAt("project-config-2020-01-02T03-04-05Z-web-cpu_scale_up", "project", "config", "web"))
+	assert.Equal(t, createdAt, *AlarmServiceIDCreatedAt("project-config-2020-01-02T03-04-05Z-web-cpu_scale_down-night", "project", "config", "web"))
+	assert.Nil(t, AlarmServiceIDCreatedAt("project-config-2020-01-02T03-04-05Z-web", "project", "config", "web"))
+	assert.Nil(t, AlarmServiceIDCreatedAt("project-config-2020-01-02T03-04-05Z-web-latency", "project", "config", "web"))
+}
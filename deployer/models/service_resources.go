@@ -9,6 +9,8 @@ import (
 	"github.com/coinbase/odin/aws/asg"
 	"github.com/coinbase/odin/aws/elb"
 	"github.com/coinbase/odin/aws/iam"
+	"github.com/coinbase/odin/aws/instancetype"
+	"github.com/coinbase/odin/aws/keypair"
 	"github.com/coinbase/odin/aws/sg"
 	"github.com/coinbase/odin/aws/subnet"
 	"github.com/coinbase/step/utils/is"
@@ -34,7 +36,9 @@ type pcsresourceIface interface {
 // ServiceResources struct
 type ServiceResources struct {
 	Image          *ami.Image
+	InstanceType   *instancetype.InstanceType
 	Profile        *iam.Profile
+	KeyPair        *keypair.KeyPair
 	PrevASG        *asg.ASG
 	SecurityGroups []*sg.SecurityGroup
 	ELBs           []*elb.LoadBalancer
@@ -51,6 +55,7 @@ type ServiceResourceNames struct {
 	ELBs           []*string `json:"elbs,omitempty"`
 	TargetGroups   []*string `json:"target_group_arns,omitempty"`
 	Subnets        []*string `json:"subnets,omitempty"`
+	EbsOptimized   *bool     `json:"ebs_optimized,omitempty"`
 }
 
 // ToServiceResourceNames returns
@@ -106,6 +111,11 @@ func (sr *ServiceResources) ToServiceResourceNames() *ServiceResourceNames {
 		subnets = append(subnets, subnet.SubnetID)
 	}
 
+	var ebsOptimized *bool
+	if sr.InstanceType != nil {
+		ebsOptimized = to.Boolp(sr.InstanceType.EbsOptimized())
+	}
+
 	return &ServiceResourceNames{
 		Image:          im,
 		Profile:        profile,
@@ -114,6 +124,7 @@ func (sr *ServiceResources) ToServiceResourceNames() *ServiceResourceNames {
 		ELBs:           elbs,
 		TargetGroups:   tgs,
 		Subnets:        subnets,
+		EbsOptimized:   ebsOptimized,
 	}
 }
 
@@ -128,6 +139,22 @@ func (sr *ServiceResources) Validate(service *Service) error {
 		return err
 	}
 
+	if err := ValidateInstanceType(service, sr.InstanceType, sr.Image); err != nil {
+		return err
+	}
+
+	if lo := service.LaunchOptions; lo != nil {
+		if err := lo.ValidateInstanceType(sr.InstanceType); err != nil {
+			return err
+		}
+
+		if lo.KeyName != nil {
+			if err := ValidateKeyPair(service, sr.KeyPair); err != nil {
+				return err
+			}
+		}
+	}
+
 	// Now the Easy Validations are over time to validate Tags and Paths
 	if err := ValidateIAMProfile(service, sr.Profile); err != nil {
 		return err
@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/coinbase/odin/aws/asg"
@@ -32,6 +33,22 @@ func Test_Service_CreateInput_HealthCheckGracePeriod(t *testing.T) {
 	assert.Equal(t, *input.HealthCheckGracePeriod, int64(10))
 }
 
+func Test_Service_LifeCycleHookSpecs_NotificationMetadata(t *testing.T) {
+	release := MockMinimalRelease(t)
+	release.LifeCycleHooks = map[string]*LifeCycleHook{
+		"TermHook": &LifeCycleHook{
+			Transistion: to.Strp("autoscaling:EC2_INSTANCE_TERMINATING"),
+		},
+	}
+
+	service := Service{}
+	service.SetDefaults(release, "web")
+
+	specs := service.LifeCycleHookSpecs()
+	assert.Equal(t, 1, len(specs))
+	assert.Equal(t, fmt.Sprintf(`{"release_id":"%v","service_name":"web"}`, *release.ReleaseID), *specs[0].NotificationMetadata)
+}
+
 func Test_Service_PlacementgroupValidation(t *testing.T) {
 	// bad strat
 	service := Service{
@@ -129,3 +146,261 @@ func Test_Service_SafeSetMinDesiredCapacity_Works(t *testing.T) {
 	assert.Equal(t, int64(3), *awsc.ASG.UpdateAutoScalingGroupLastInput.DesiredCapacity)
 	assert.Equal(t, int64(2), *awsc.ASG.UpdateAutoScalingGroupLastInput.MinSize)
 }
+
+func Test_Service_BlockDevices_LaunchConfiguration(t *testing.T) {
+	release := MockRelease(t)
+	MockPrepareRelease(release)
+
+	service := release.Services["web"]
+	service.BlockDevices = []*BlockDevice{
+		&BlockDevice{DeviceName: to.Strp("/dev/xvdb"), VolumeSize: to.Int64p(500), VolumeType: to.Strp("gp3"), Iops: to.Int64p(6000), Throughput: to.Int64p(500)},
+		&BlockDevice{DeviceName: to.Strp("/dev/sdb"), VirtualName: to.Strp("ephemeral0")},
+	}
+	release.SetDefaults()
+
+	// Cannot be used with the EBS attributes
+	assert.Error(t, service.Validate())
+
+	service.EBSVolumeSize = nil
+	assert.NoError(t, service.Validate())
+
+	input := service.createLaunchConfigurationInput()
+	assert.Equal(t, 2, len(input.BlockDeviceMappings))
+	assert.Equal(t, int64(500), *input.BlockDeviceMappings[0].Ebs.Throughput)
+	assert.Equal(t, "ephemeral0", *input.BlockDeviceMappings[1].VirtualName)
+
+	// KMS keys need a launch template
+	service.BlockDevices[0].KmsKeyID = to.Strp("key")
+	service.BlockDevices[0].SetDefaults()
+	err := service.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "LaunchTemplate")
+
+	// Device names must be unique
+	service.BlockDevices[0].KmsKeyID = nil
+	service.BlockDevices[1].DeviceName = to.Strp("/dev/xvdb")
+	assert.Error(t, service.Validate())
+}
+
+func Test_Service_BlockDevices_LaunchTemplate(t *testing.T) {
+	release := MockRelease(t)
+	MockPrepareRelease(release)
+
+	service := release.Services["web"]
+	service.LaunchTemplate = true
+	service.EBSVolumeSize = nil
+	service.BlockDevices = []*BlockDevice{
+		&BlockDevice{DeviceName: to.Strp("/dev/xvda"), VolumeSize: to.Int64p(20)},
+		&BlockDevice{DeviceName: to.Strp("/dev/xvdb"), VolumeSize: to.Int64p(500), KmsKeyID: to.Strp("key"), DeleteOnTermination: to.Boolp(false)},
+	}
+	release.SetDefaults()
+
+	assert.NoError(t, service.Validate())
+
+	asgInput := service.createInput()
+	assert.Nil(t, asgInput.LaunchConfigurationName)
+	assert.Equal(t, *service.ServiceID(), *asgInput.LaunchTemplate.LaunchTemplateName)
+
+	input := service.createLaunchTemplateInput()
+	assert.Equal(t, *service.ServiceID(), *input.LaunchTemplateName)
+
+	mappings := input.LaunchTemplateData.BlockDeviceMappings
+	assert.Equal(t, 2, len(mappings))
+	assert.Equal(t, "key", *mappings[1].Ebs.KmsKeyId)
+	assert.True(t, *mappings[1].Ebs.Encrypted)
+	assert.False(t, *mappings[1].Ebs.DeleteOnTermination)
+}
+
+func Test_Service_MetadataOptions(t *testing.T) {
+	release := MockRelease(t)
+	MockPrepareRelease(release)
+
+	service := release.Services["web"]
+	service.MetadataOptions = &MetadataOptions{
+		HttpTokens:              to.Strp("required"),
+		HttpPutResponseHopLimit: to.Int64p(2),
+		InstanceMetadataTags:    to.Strp("enabled"),
+	}
+	release.SetDefaults()
+
+	err := service.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "requires LaunchTemplate")
+	}
+
+	service.MetadataOptions.InstanceMetadataTags = nil
+	assert.NoError(t, service.Validate())
+
+	lci := service.createLaunchConfigurationInput()
+	assert.Equal(t, "required", *lci.MetadataOptions.HttpTokens)
+	assert.Equal(t, int64(2), *lci.MetadataOptions.HttpPutResponseHopLimit)
+
+	service.LaunchTemplate = true
+	service.MetadataOptions.InstanceMetadataTags = to.Strp("enabled")
+	assert.NoError(t, service.Validate())
+
+	lti := service.createLaunchTemplateInput()
+	assert.Equal(t, "required", *lti.LaunchTemplateData.MetadataOptions.HttpTokens)
+	assert.Equal(t, "enabled", *lti.LaunchTemplateData.MetadataOptions.InstanceMetadataTags)
+}
+
+func Test_Service_LaunchOptions(t *testing.T) {
+	release := MockRelease(t)
+	MockPrepareRelease(release)
+
+	service := release.Services["web"]
+	service.LaunchOptions = &LaunchOptions{
+		DetailedMonitoring:  to.Boolp(true),
+		CreditSpecification: to.Strp("unlimited"),
+		KeyName:             to.Strp("deploy"),
+		EbsOptimized:        to.Boolp(true),
+	}
+	release.SetDefaults()
+
+	err := service.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "CreditSpecification requires LaunchTemplate")
+	}
+
+	service.LaunchOptions.CreditSpecification = nil
+	assert.NoError(t, service.Validate())
+
+	lci := service.createLaunchConfigurationInput()
+	assert.True(t, *lci.InstanceMonitoring.Enabled)
+	assert.True(t, *lci.EbsOptimized)
+	assert.Equal(t, "deploy", *lci.KeyName)
+
+	service.LaunchTemplate = true
+	service.LaunchOptions.CreditSpecification = to.Strp("unlimited")
+	assert.NoError(t, service.Validate())
+
+	lti := service.createLaunchTemplateInput()
+	assert.True(t, *lti.LaunchTemplateData.Monitoring.Enabled)
+	assert.Equal(t, "unlimited", *lti.LaunchTemplateData.CreditSpecification.CpuCredits)
+	assert.Equal(t, "deploy", *lti.LaunchTemplateData.KeyName)
+
+	service.LaunchOptions.CreditSpecification = to.Strp("burst")
+	assert.Error(t, service.Validate())
+}
+
+func Test_Service_AutoscalingAttributes(t *testing.T) {
+	release := MockRelease(t)
+	MockPrepareRelease(release)
+
+	service := release.Services["web"]
+	service.Autoscaling.HealthCheckType = to.Strp("EC2")
+	service.Autoscaling.TerminationPolicies = []*string{to.Strp("OldestLaunchConfiguration"), to.Strp("Default")}
+	service.Autoscaling.MaxInstanceLifetime = to.Int64p(604800)
+	service.Autoscaling.NewInstancesProtectedFromScaleIn = to.Boolp(true)
+	release.SetDefaults()
+
+	assert.NoError(t, service.Validate())
+
+	input := service.createInput()
+	assert.Equal(t, "EC2", *input.HealthCheckType)
+	assert.Equal(t, []string{"OldestLaunchConfiguration", "Default"}, to.StrSlice(input.TerminationPolicies))
+	assert.Equal(t, int64(604800), *input.MaxInstanceLifetime)
+	assert.True(t, *input.NewInstancesProtectedFromScaleIn)
+
+	service.LaunchTemplate = true
+	err := service.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "cannot be used with LaunchTemplate")
+	}
+	service.LaunchTemplate = false
+
+	service.Autoscaling.CapacityRebalance = to.Boolp(true)
+	err = service.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "CapacityRebalance requires SpotPrice")
+	}
+
+	service.SpotPrice = to.Strp("0.1")
+	assert.NoError(t, service.Validate())
+	assert.True(t, *service.createInput().CapacityRebalance)
+
+	service.ELBs = nil
+	service.TargetGroups = nil
+	service.Autoscaling.HealthCheckType = to.Strp("ELB")
+	err = service.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "requires ELBs or TargetGroups")
+	}
+}
+
+func Test_Service_PropagateTags(t *testing.T) {
+	release := MockRelease(t)
+	MockPrepareRelease(release)
+
+	service := release.Services["web"]
+	service.Tags = map[string]*string{"team": to.Strp("infra")}
+	release.SetDefaults()
+
+	// Default tags the instances
+	assert.NoError(t, service.Validate())
+	for _, tag := range service.createInput().Tags {
+		assert.True(t, *tag.PropagateAtLaunch)
+	}
+	assert.Nil(t, service.createLaunchTemplateInput().LaunchTemplateData.TagSpecifications)
+
+	service.PropagateTags = to.Strp("volumes")
+	err := service.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "requires LaunchTemplate")
+	}
+
+	// Only the Odin tags are on the instances
+	service.LaunchTemplate = true
+	assert.NoError(t, service.Validate())
+	for _, tag := range service.createInput().Tags {
+		assert.Equal(t, isOdinTagKey(*tag.Key), *tag.PropagateAtLaunch, *tag.Key)
+	}
+
+	specs := service.createLaunchTemplateInput().LaunchTemplateData.TagSpecifications
+	if assert.Equal(t, 1, len(specs)) {
+		assert.Equal(t, "volume", *specs[0].ResourceType)
+		assert.Equal(t, 7, len(specs[0].Tags))
+	}
+
+	service.PropagateTags = to.Strp("all")
+	assert.NoError(t, service.Validate())
+	for _, tag := range service.createInput().Tags {
+		assert.True(t, *tag.PropagateAtLaunch)
+	}
+	assert.Equal(t, 1, len(service.createLaunchTemplateInput().LaunchTemplateData.TagSpecifications))
+
+	service.PropagateTags = to.Strp("everything")
+	assert.Error(t, service.Validate())
+}
+
+func Test_Service_Tags_Limits(t *testing.T) {
+	release := MockRelease(t)
+	MockPrepareRelease(release)
+
+	service := release.Services["web"]
+	release.SetDefaults()
+
+	service.Tags = map[string]*string{"aws:cloudformation:stack-name": to.Strp("x")}
+	err := service.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "reserved aws: prefix")
+	}
+
+	service.Tags = map[string]*string{strings.Repeat("k", 129): to.Strp("x")}
+	assert.Error(t, service.Validate())
+
+	service.Tags = map[string]*string{"key": to.Strp(strings.Repeat("v", 257))}
+	assert.Error(t, service.Validate())
+
+	service.Tags = map[string]*string{}
+	for i := 0; i < 44; i++ {
+		service.Tags[fmt.Sprintf("tag%v", i)] = to.Strp("x")
+	}
+	assert.NoError(t, service.Validate())
+
+	service.Tags["tag44"] = to.Strp("x")
+	err = service.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Tags too many")
+	}
+}
@@ -0,0 +1,199 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/coinbase/odin/aws"
+	"github.com/coinbase/odin/aws/alarms"
+	"github.com/coinbase/odin/aws/asg"
+	"github.com/coinbase/odin/aws/lc"
+	"github.com/coinbase/odin/aws/lt"
+	"github.com/coinbase/step/utils/to"
+)
+
+// Default and minimum age in seconds of a resource before it is swept,
+// the minimum leaves time for a running deploy to create the ASG for its launch configuration and alarms
+const defaultSweepOlderThan = 86400
+const minSweepOlderThan = 3600
+
+// Sweep is the input and result of the Sweep task, which deletes launch configurations, launch templates and alarms
+// named with a ServiceID that are left behind without an ASG. Only the services of project configs
+// that still have an Odin ASG are swept, so resources that are not Odin's are never matched
+type Sweep struct {
+	AwsAccountID *string `json:"aws_account_id,omitempty"`
+	AwsRegion    *string `json:"aws_region,omitempty"`
+
+	// Only resources older than this many seconds are swept
+	OlderThan *int `json:"older_than,omitempty"`
+
+	// DryRun only reports what would be deleted, it defaults to true
+	DryRun *bool `json:"dry_run,omitempty"`
+
+	// Results
+	LaunchConfigurations []string `json:"launch_configurations,omitempty"`
+	LaunchTemplates      []string `json:"launch_templates,omitempty"`
+	Alarms               []string `json:"alarms,omitempty"`
+}
+
+// SetDefaults assigns default values
+func (sweep *Sweep) SetDefaults(region *string, account *string) {
+	if sweep.AwsRegion == nil {
+		sweep.AwsRegion = region
+	}
+
+	if sweep.AwsAccountID == nil {
+		sweep.AwsAccountID = account
+	}
+
+	if sweep.OlderThan == nil {
+		sweep.OlderThan = to.Intp(defaultSweepOlderThan)
+	}
+
+	if sweep.DryRun == nil {
+		sweep.DryRun = to.Boolp(true)
+	}
+
+	// Results are always recalculated
+	sweep.LaunchConfigurations = []string{}
+	sweep.LaunchTemplates = []string{}
+	sweep.Alarms = []string{}
+}
+
+// Validate returns
+func (sweep *Sweep) Validate() error {
+	if sweep.AwsRegion == nil || sweep.AwsAccountID == nil {
+		return fmt.Errorf("AwsRegion and AwsAccountID must be defined")
+	}
+
+	if *sweep.OlderThan < minSweepOlderThan {
+		return fmt.Errorf("OlderThan must be at least %v seconds", minSweepOlderThan)
+	}
+
+	return nil
+}
+
+// Run finds the orphaned launch configurations, launch templates and alarms, and deletes them unless DryRun
+func (sweep *Sweep) Run(asgc aws.ASGAPI, cwc aws.CWAPI, ec2c aws.EC2API, now time.Time) error {
+	cutoff := now.Add(-time.Duration(*sweep.OlderThan) * time.Second)
+
+	asgs, err := asg.All(asgc)
+	if err != nil {
+		return err
+	}
+
+	usedLCs := map[string]bool{}
+	usedLTs := map[string]bool{}
+	alarmPrefixes := []string{}
+	services := map[sweepService]bool{}
+	for _, group := range asgs {
+		if group.LaunchConfigurationName != nil {
+			usedLCs[*group.LaunchConfigurationName] = true
+		}
+
+		if group.LaunchTemplateName != nil {
+			usedLTs[*group.LaunchTemplateName] = true
+		}
+
+		// Alarms are named "<ServiceID>-<type>" where the ServiceID is the ASG name
+		if group.ServiceID() != nil {
+			alarmPrefixes = append(alarmPrefixes, fmt.Sprintf("%v-", *group.ServiceID()))
+		}
+
+		if group.ProjectName() != nil && group.ConfigName() != nil && group.ServiceName() != nil {
+			services[sweepService{*group.ProjectName(), *group.ConfigName(), *group.ServiceName()}] = true
+		}
+	}
+
+	lcs, err := lc.FindByPrefix(asgc, "")
+	if err != nil {
+		return err
+	}
+
+	for _, l := range lcs {
+		name := to.Strs(l.LaunchConfigurationName)
+		if usedLCs[name] || !sweepable(serviceIDCreatedAtIn(name, services, ServiceIDCreatedAt), l.CreatedTime, cutoff) {
+			continue
+		}
+
+		sweep.LaunchConfigurations = append(sweep.LaunchConfigurations, name)
+	}
+
+	lts, err := lt.FindByPrefix(ec2c, "")
+	if err != nil {
+		return err
+	}
+
+	for _, l := range lts {
+		name := to.Strs(l.LaunchTemplateName)
+		if usedLTs[name] || !sweepable(serviceIDCreatedAtIn(name, services, ServiceIDCreatedAt), l.CreateTime, cutoff) {
+			continue
+		}
+
+		sweep.LaunchTemplates = append(sweep.LaunchTemplates, name)
+	}
+
+	metricAlarms, err := alarms.FindByPrefix(cwc, "")
+	if err != nil {
+		return err
+	}
+
+	for _, a := range metricAlarms {
+		name := to.Strs(a.AlarmName)
+		if HasAnyPrefix(name, alarmPrefixes) || !sweepable(serviceIDCreatedAtIn(name, services, AlarmServiceIDCreatedAt), a.AlarmConfigurationUpdatedTimestamp, cutoff) {
+			continue
+		}
+
+		sweep.Alarms = append(sweep.Alarms, name)
+	}
+
+	if *sweep.DryRun {
+		return nil
+	}
+
+	for _, name := range sweep.LaunchConfigurations {
+		if err := lc.Teardown(asgc, to.Strp(name)); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range sweep.LaunchTemplates {
+		if err := lt.Teardown(ec2c, to.Strp(name)); err != nil {
+			return err
+		}
+	}
+
+	alarmNames := []*string{}
+	for _, name := range sweep.Alarms {
+		alarmNames = append(alarmNames, to.Strp(name))
+	}
+
+	return alarms.Teardown(cwc, alarmNames)
+}
+
+// sweepService is a service of a project config that has an Odin ASG
+type sweepService struct {
+	project string
+	config  string
+	service string
+}
+
+// serviceIDCreatedAtIn returns the created at time of name if it is a resource of one of the services, nil otherwise
+func serviceIDCreatedAtIn(name string, services map[sweepService]bool, createdAtFn func(string, string, string, string) *time.Time) *time.Time {
+	for ss := range services {
+		if createdAt := createdAtFn(name, ss.project, ss.config, ss.service); createdAt != nil {
+			return createdAt
+		}
+	}
+
+	return nil
+}
+
+// sweepable returns true if the ServiceID and the resource were both created before cutoff
+func sweepable(createdAt *time.Time, resourceTime *time.Time, cutoff time.Time) bool {
+	if createdAt == nil || !createdAt.Before(cutoff) {
+		return false
+	}
+
+	return resourceTime == nil || resourceTime.Before(cutoff)
+}
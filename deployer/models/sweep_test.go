@@ -0,0 +1,102 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/coinbase/odin/aws/mocks"
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockSweepResources() *mocks.MockClients {
+	awsc := mocks.MockAWS()
+
+	// A live ASG with its launch configuration and alarm
+	live := "project-config-2020-01-01T00-00-00Z-web"
+	group := mocks.MakeMockASG(live, "project", "config", "web", "live")
+	group.LaunchConfigurationName = to.Strp(live)
+	awsc.ASG.AddASG(group)
+	awsc.ASG.AddLaunchConfiguration(live)
+
+	// A launch template service with its launch template
+	liveLT := "project-config-2020-01-01T00-00-00Z-worker"
+	ltGroup := mocks.MakeMockASG(liveLT, "project", "config", "worker", "live")
+	ltGroup.LaunchTemplate = &autoscaling.LaunchTemplateSpecification{LaunchTemplateName: to.Strp(liveLT)}
+	awsc.ASG.AddASG(ltGroup)
+	awsc.EC2.AddLaunchTemplate(liveLT)
+
+	// Leaked by failed deploys
+	awsc.EC2.AddLaunchTemplate("project-config-2020-01-03T00-00-00Z-worker")
+	awsc.EC2.AddLaunchTemplate("project-config-2020-01-09T23-00-00Z-worker") // too new
+	awsc.EC2.AddLaunchTemplate("other-team-2020-01-03T00-00-00Z-worker")     // not odin's
+	awsc.ASG.AddLaunchConfiguration("project-config-2020-01-02T00-00-00Z-web")
+	awsc.ASG.AddLaunchConfiguration("project-config-2020-01-09T23-00-00Z-web") // too new
+
+	// Not created by odin, or for a project config odin does not know
+	awsc.ASG.AddLaunchConfiguration("some-other-launch-config")
+	awsc.ASG.AddLaunchConfiguration("other-team-2020-01-02T00-00-00Z-web")
+	awsc.ASG.AddLaunchConfiguration("project-config-2020-01-02T00-00-00Z-web-backup")
+
+	for _, name := range []string{
+		live + "-cpu_scale_up",
+		"project-config-2020-01-02T00-00-00Z-web-cpu_scale_up",
+		"project-config-2020-01-09T23-00-00Z-web-cpu_scale_up",
+		"some-other-alarm",
+		"other-team-2020-01-02T00-00-00Z-web-cpu_scale_up",
+		"project-config-2020-01-02T00-00-00Z-web-latency",
+	} {
+		awsc.CW.MetricAlarms = append(awsc.CW.MetricAlarms, &cloudwatch.MetricAlarm{AlarmName: to.Strp(name)})
+	}
+
+	return awsc
+}
+
+func Test_Sweep_Validate(t *testing.T) {
+	sweep := &Sweep{}
+	sweep.SetDefaults(to.Strp("region"), to.Strp("account"))
+	assert.NoError(t, sweep.Validate())
+	assert.True(t, *sweep.DryRun)
+	assert.Equal(t, 86400, *sweep.OlderThan)
+
+	sweep.OlderThan = to.Intp(60)
+	assert.Error(t, sweep.Validate())
+
+	sweep = &Sweep{}
+	sweep.SetDefaults(nil, nil)
+	assert.Error(t, sweep.Validate())
+}
+
+func Test_Sweep_DryRun(t *testing.T) {
+	awsc := mockSweepResources()
+
+	sweep := &Sweep{}
+	sweep.SetDefaults(to.Strp("region"), to.Strp("account"))
+
+	now := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, sweep.Run(awsc.ASG, awsc.CW, awsc.EC2, now))
+
+	assert.Equal(t, []string{"project-config-2020-01-02T00-00-00Z-web"}, sweep.LaunchConfigurations)
+	assert.Equal(t, []string{"project-config-2020-01-03T00-00-00Z-worker"}, sweep.LaunchTemplates)
+	assert.Equal(t, []string{"project-config-2020-01-02T00-00-00Z-web-cpu_scale_up"}, sweep.Alarms)
+
+	assert.Equal(t, 0, len(awsc.ASG.DeletedLaunchConfigurations))
+	assert.Equal(t, 0, len(awsc.CW.DeletedAlarms))
+	assert.Equal(t, 0, len(awsc.EC2.DeletedLaunchTemplates))
+}
+
+func Test_Sweep_Deletes(t *testing.T) {
+	awsc := mockSweepResources()
+
+	sweep := &Sweep{DryRun: to.Boolp(false)}
+	sweep.SetDefaults(to.Strp("region"), to.Strp("account"))
+
+	now := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, sweep.Run(awsc.ASG, awsc.CW, awsc.EC2, now))
+
+	assert.Equal(t, []string{"project-config-2020-01-02T00-00-00Z-web"}, awsc.ASG.DeletedLaunchConfigurations)
+	assert.Equal(t, []string{"project-config-2020-01-03T00-00-00Z-worker"}, awsc.EC2.DeletedLaunchTemplates)
+	assert.Equal(t, []string{"project-config-2020-01-02T00-00-00Z-web-cpu_scale_up"}, awsc.CW.DeletedAlarms)
+}
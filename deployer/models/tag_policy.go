@@ -0,0 +1,93 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/coinbase/step/utils/to"
+)
+
+// TagPolicy limits the tags services can be deployed with
+type TagPolicy struct {
+	// RequiredKeys must be tagged on every service e.g. ["CostCenter", "Owner"]
+	RequiredKeys []string `json:"required_keys,omitempty"`
+
+	// AllowedValues are regular expressions the whole value of a key must match e.g. {"Owner": "team-.+"}
+	AllowedValues map[string]string `json:"allowed_values,omitempty"`
+
+	// ForbiddenKeys cannot be tagged on any service
+	ForbiddenKeys []string `json:"forbidden_keys,omitempty"`
+}
+
+// Violations returns how the tags break the policy, empty if they do not
+func (p *TagPolicy) Violations(tags map[string]*string) []string {
+	violations := []string{}
+	if p == nil {
+		return violations
+	}
+
+	for _, key := range p.RequiredKeys {
+		if to.Strs(tags[key]) == "" {
+			violations = append(violations, fmt.Sprintf("tag %v is required", key))
+		}
+	}
+
+	keys := []string{}
+	for key := range p.AllowedValues {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value, ok := tags[key]
+		if !ok {
+			continue
+		}
+
+		re, err := regexp.Compile(fmt.Sprintf("^(?:%v)$", p.AllowedValues[key]))
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("tag %v allowed values %q is not a valid regex", key, p.AllowedValues[key]))
+			continue
+		}
+
+		if !re.MatchString(to.Strs(value)) {
+			violations = append(violations, fmt.Sprintf("tag %v value %q must match %q", key, to.Strs(value), p.AllowedValues[key]))
+		}
+	}
+
+	for _, key := range p.ForbiddenKeys {
+		if _, ok := tags[key]; ok {
+			violations = append(violations, fmt.Sprintf("tag %v is forbidden", key))
+		}
+	}
+
+	return violations
+}
+
+// ValidateTagPolicy errors if any service's tags break the Odin-wide tag policy
+func (release *Release) ValidateTagPolicy(config *OdinConfig) error {
+	if config == nil || config.TagPolicy == nil {
+		return nil
+	}
+
+	names := []string{}
+	for name := range release.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		service := release.Services[name]
+		if service == nil {
+			continue
+		}
+
+		if violations := config.TagPolicy.Violations(service.Tags); len(violations) > 0 {
+			return fmt.Errorf("Service %v Tags violate the tag policy: %v", name, strings.Join(violations, ", "))
+		}
+	}
+
+	return nil
+}
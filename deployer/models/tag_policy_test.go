@@ -0,0 +1,79 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/coinbase/step/utils/to"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TagPolicy_Violations(t *testing.T) {
+	var nilPolicy *TagPolicy
+	assert.Equal(t, 0, len(nilPolicy.Violations(nil)))
+
+	p := &TagPolicy{
+		RequiredKeys:  []string{"CostCenter", "Owner"},
+		AllowedValues: map[string]string{"Owner": "team-.+"},
+		ForbiddenKeys: []string{"Temporary"},
+	}
+
+	assert.Equal(t, 0, len(p.Violations(map[string]*string{
+		"CostCenter": to.Strp("1234"),
+		"Owner":      to.Strp("team-infra"),
+	})))
+
+	assert.Equal(t, []string{
+		"tag CostCenter is required",
+		`tag Owner value "infra" must match "team-.+"`,
+		"tag Temporary is forbidden",
+	}, p.Violations(map[string]*string{
+		"Owner":     to.Strp("infra"),
+		"Temporary": to.Strp("yes"),
+	}))
+
+	// Values match the whole regex
+	assert.Equal(t, 1, len(p.Violations(map[string]*string{
+		"CostCenter": to.Strp("1234"),
+		"Owner":      to.Strp("my-team-infra"),
+	})))
+}
+
+func Test_Release_Validate_TagPolicy(t *testing.T) {
+	release := MockRelease(t)
+	release.Tags = map[string]*string{"CostCenter": to.Strp("1234"), "Owner": to.Strp("team-infra")}
+	release.Services["web"].Tags = map[string]*string{"Owner": to.Strp("infra")}
+	awsc := MockAwsClients(release)
+	release.ReleaseSHA256 = to.SHA256Struct(release)
+	MockPrepareRelease(release)
+
+	// Release tags merge into the service, the service wins
+	assert.Equal(t, "1234", *release.Services["web"].Tags["CostCenter"])
+	assert.Equal(t, "infra", *release.Services["web"].Tags["Owner"])
+
+	// No OdinConfig uploaded
+	assert.NoError(t, release.Validate(awsc.S3))
+
+	awsc.S3.AddGetObject(*OdinConfigPath(), `{"tag_policy": {"required_keys": ["CostCenter"], "allowed_values": {"Owner": "team-.+"}}}`, nil)
+
+	err := release.Validate(awsc.S3)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `Service web Tags violate the tag policy: tag Owner value "infra" must match "team-.+"`)
+	}
+
+	release.Services["web"].Tags["Owner"] = to.Strp("team-web")
+	assert.NoError(t, release.Validate(awsc.S3))
+}
+
+func Test_Service_Tags_OdinKeys(t *testing.T) {
+	release := MockRelease(t)
+	MockPrepareRelease(release)
+
+	service := release.Services["web"]
+	service.Tags = map[string]*string{"ReleaseID": to.Strp("mine")}
+	release.SetDefaults()
+
+	err := service.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "reserved for Odin")
+	}
+}
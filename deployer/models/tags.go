@@ -0,0 +1,135 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/coinbase/step/utils/to"
+)
+
+// AWS tag limits per resource
+const maxTags = 50
+const maxTagKeyLength = 128
+const maxTagValueLength = 256
+
+// Tags Odin adds to every ASG
+var odinTagKeys = []string{"ProjectName", "ConfigName", "ServiceName", "ReleaseID", "ReleaseUUID", "Name"}
+
+// propagateTags returns where the ASG tags are copied to, "instances" (default), "volumes" or "all"
+func (service *Service) propagateTags() string {
+	if service.PropagateTags == nil {
+		return "instances"
+	}
+	return *service.PropagateTags
+}
+
+// tagsToInstances returns true if instances are tagged with the custom tags through the ASG
+func (service *Service) tagsToInstances() bool {
+	switch service.propagateTags() {
+	case "instances", "all":
+		return true
+	}
+	return false
+}
+
+// tagsToVolumes returns true if volumes are tagged through the launch template
+func (service *Service) tagsToVolumes() bool {
+	switch service.propagateTags() {
+	case "volumes", "all":
+		return true
+	}
+	return false
+}
+
+// tags returns the custom tags with the Odin tags
+func (service *Service) tags() map[string]*string {
+	tags := map[string]*string{}
+	for key, value := range service.Tags {
+		tags[key] = value
+	}
+
+	tags["ProjectName"] = service.ProjectName()
+	tags["ConfigName"] = service.ConfigName()
+	tags["ServiceName"] = service.ServiceName
+	tags["ReleaseID"] = service.ReleaseID()
+	tags["ReleaseUUID"] = service.ReleaseUUID()
+	tags["Name"] = service.ServiceID()
+
+	return tags
+}
+
+// volumeTagSpecifications returns the launch template tag specification for the volumes
+func (service *Service) volumeTagSpecifications() []*ec2.LaunchTemplateTagSpecificationRequest {
+	tags := service.tags()
+
+	keys := []string{}
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	ec2Tags := []*ec2.Tag{}
+	for _, key := range keys {
+		ec2Tags = append(ec2Tags, &ec2.Tag{Key: to.Strp(key), Value: tags[key]})
+	}
+
+	return []*ec2.LaunchTemplateTagSpecificationRequest{
+		&ec2.LaunchTemplateTagSpecificationRequest{
+			ResourceType: to.Strp(ec2.ResourceTypeVolume),
+			Tags:         ec2Tags,
+		},
+	}
+}
+
+// validateTags checks the custom tags against the AWS limits
+func (service *Service) validateTags() error {
+	switch service.propagateTags() {
+	case "instances", "volumes", "all":
+		// valid
+	default:
+		return fmt.Errorf("PropagateTags must be either 'instances', 'volumes' or 'all'")
+	}
+
+	if service.tagsToVolumes() && !service.LaunchTemplate {
+		return fmt.Errorf("PropagateTags %v requires LaunchTemplate to tag volumes", service.propagateTags())
+	}
+
+	for key, value := range service.Tags {
+		if key == "" {
+			return fmt.Errorf("Tags key must not be empty")
+		}
+
+		if isOdinTagKey(key) {
+			return fmt.Errorf("Tags key %q is reserved for Odin", key)
+		}
+
+		if strings.HasPrefix(strings.ToLower(key), "aws:") {
+			return fmt.Errorf("Tags key %q uses the reserved aws: prefix", key)
+		}
+
+		if len(key) > maxTagKeyLength {
+			return fmt.Errorf("Tags key %q longer than %v characters", key, maxTagKeyLength)
+		}
+
+		if len(to.Strs(value)) > maxTagValueLength {
+			return fmt.Errorf("Tags value of %q longer than %v characters", key, maxTagValueLength)
+		}
+	}
+
+	if len(service.Tags)+len(odinTagKeys) > maxTags {
+		return fmt.Errorf("Tags too many, %v custom tags and %v Odin tags is more than %v", len(service.Tags), len(odinTagKeys), maxTags)
+	}
+
+	return nil
+}
+
+func isOdinTagKey(key string) bool {
+	for _, k := range odinTagKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
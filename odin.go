@@ -13,18 +13,17 @@ import (
 
 func main() {
 	var arg, command string
+	var args []string
 	switch len(os.Args) {
 	case 1:
 		fmt.Println("Starting Lambda")
 		run.LambdaTasks(deployer.TaskHandlers())
-	case 2:
-		command = os.Args[1]
-		arg = ""
-	case 3:
-		command = os.Args[1]
-		arg = os.Args[2]
 	default:
-		printUsage() // Print how to use and exit
+		command = os.Args[1]
+		args = os.Args[2:]
+		if len(args) > 0 {
+			arg = args[0]
+		}
 	}
 
 	stepFn := to.Strp(os.Getenv("ODIN_STEP"))
@@ -38,15 +37,52 @@ func main() {
 		run.JSON(deployer.StateMachine())
 	case "deploy":
 		// Send Configuration to the deployer
-		// arg is a filename
-		err := client.Deploy(stepFn, &arg)
+		opts, err := client.DeployOptionsFromArgs(args)
+		if err != nil {
+			fmt.Println(err.Error())
+			printUsage()
+		}
+
+		err = client.Deploy(stepFn, opts)
 		if err != nil {
 			fmt.Println(err.Error())
 			os.Exit(1)
 		}
 	case "fails":
 		// List the recent failures and their causes
-		err := client.Failures(stepFn)
+		opts, err := client.FailuresOptionsFromArgs(args)
+		if err != nil {
+			fmt.Println(err.Error())
+			printUsage()
+		}
+
+		err = client.Failures(stepFn, opts)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+	case "history":
+		// List the past releases of a project-config
+		opts, err := client.HistoryOptionsFromArgs(args)
+		if err != nil {
+			fmt.Println(err.Error())
+			printUsage()
+		}
+
+		err = client.History(stepFn, opts)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+	case "doctor":
+		// Find and optionally fix resources left behind for a project-config
+		opts, err := client.DoctorOptionsFromArgs(args)
+		if err != nil {
+			fmt.Println(err.Error())
+			printUsage()
+		}
+
+		err = client.Doctor(stepFn, opts)
 		if err != nil {
 			fmt.Println(err.Error())
 			os.Exit(1)
@@ -63,6 +99,10 @@ func main() {
 }
 
 func printUsage() {
-	fmt.Println("Usage: odin <json|deploy|halt|fails> <release_file> (No args starts Lambda)")
+	fmt.Println("Usage: odin <json|deploy|halt> <release_file> (No args starts Lambda)")
+	fmt.Println("       odin deploy <release_file> [--only service,service]")
+	fmt.Println("       odin history <project> <config> [--since 720h] [--limit n] [--json] [--diff <release_id> <release_id>]")
+	fmt.Println("       odin doctor <project> <config> [--fix] [--live <release_id>]")
+	fmt.Println("       odin fails [--since 72h] [--project p] [--config c] [--state FailureClean|FailureDirty] [--limit n] [--json]")
 	os.Exit(0)
 }